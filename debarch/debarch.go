@@ -0,0 +1,300 @@
+package debarch
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Ref.: dpkg-architecture(1), and Dpkg::Arch from dpkg.
+
+// Tuple is the decomposition of a Debian architecture into its ABI, libc, OS and CPU parts, e.g. "base-gnu-linux-amd64"
+// for "amd64" or "eabihf-gnu-linux-arm" for "armhf".  In wildcards, any part may be "any".
+type Tuple struct {
+	ABI  string
+	LibC string
+	OS   string
+	CPU  string
+}
+
+func (t Tuple) String() string {
+	return t.ABI + "-" + t.LibC + "-" + t.OS + "-" + t.CPU
+}
+
+// ParseTuple parses the string form of a tuple (e.g. "base-gnu-linux-amd64").
+func ParseTuple(s string) (Tuple, error) {
+	parts := strings.Split(s, "-")
+	if len(parts) != 4 {
+		return Tuple{}, errors.Errorf("malformed architecture tuple: %q", s)
+	}
+	return Tuple{ABI: parts[0], LibC: parts[1], OS: parts[2], CPU: parts[3]}, nil
+}
+
+// CPU describes an entry from dpkg's cputable.
+type CPU struct {
+	Name    string
+	GNUName string
+	// Bits is the CPU's natural word size, before any adjustment by the ABI (e.g. for x32).
+	Bits      int
+	BigEndian bool
+
+	gnuRegexp *regexp.Regexp
+}
+
+type osEntry struct {
+	triplet   string // ABI-libc-OS
+	gnuName   string
+	gnuRegexp *regexp.Regexp
+}
+
+type tables struct {
+	cpus        []CPU
+	cpuByName   map[string]*CPU
+	oses        []osEntry
+	osByTriplet map[string]*osEntry
+	abiBits     map[string]int
+	toTuple     map[string]Tuple
+	fromTuple   map[Tuple]string
+	arches      []string
+}
+
+var (
+	loadOnce sync.Once
+	loaded   *tables
+)
+
+func tableRows(table string) [][]string {
+	var rows [][]string
+	for _, line := range strings.Split(table, "\n") {
+		if fields := strings.Fields(line); len(fields) > 0 {
+			rows = append(rows, fields)
+		}
+	}
+	return rows
+}
+
+func load() *tables {
+	loadOnce.Do(func() {
+		t := &tables{
+			cpuByName:   make(map[string]*CPU),
+			osByTriplet: make(map[string]*osEntry),
+			abiBits:     make(map[string]int),
+			toTuple:     make(map[string]Tuple),
+			fromTuple:   make(map[Tuple]string),
+		}
+
+		for _, row := range tableRows(cputable) {
+			bits, _ := strconv.Atoi(row[3])
+			t.cpus = append(t.cpus, CPU{
+				Name:      row[0],
+				GNUName:   row[1],
+				Bits:      bits,
+				BigEndian: row[4] == "big",
+				gnuRegexp: regexp.MustCompile("^(?:" + row[2] + ")$"),
+			})
+		}
+		for i := range t.cpus {
+			t.cpuByName[t.cpus[i].Name] = &t.cpus[i]
+		}
+
+		for _, row := range tableRows(ostable) {
+			t.oses = append(t.oses, osEntry{
+				triplet:   row[0],
+				gnuName:   row[1],
+				gnuRegexp: regexp.MustCompile("^(?:" + row[2] + ")$"),
+			})
+		}
+		for i := range t.oses {
+			t.osByTriplet[t.oses[i].triplet] = &t.oses[i]
+		}
+
+		for _, row := range tableRows(abitable) {
+			bits, _ := strconv.Atoi(row[1])
+			t.abiBits[row[0]] = bits
+		}
+
+		add := func(tupleStr, arch string) {
+			tuple, err := ParseTuple(tupleStr)
+			if err != nil {
+				panic(err)
+			}
+			if _, ok := t.toTuple[arch]; ok {
+				return
+			}
+			if _, ok := t.fromTuple[tuple]; ok {
+				return
+			}
+			t.toTuple[arch] = tuple
+			t.fromTuple[tuple] = arch
+			t.arches = append(t.arches, arch)
+		}
+		for _, row := range tableRows(tupletable) {
+			if !strings.Contains(row[0], "<cpu>") {
+				add(row[0], row[1])
+				continue
+			}
+			for _, cpu := range t.cpus {
+				add(strings.Replace(row[0], "<cpu>", cpu.Name, -1), strings.Replace(row[1], "<cpu>", cpu.Name, -1))
+			}
+		}
+
+		loaded = t
+	})
+	return loaded
+}
+
+// Known returns every architecture name that dpkg knows about.
+func Known() []string {
+	return append([]string(nil), load().arches...)
+}
+
+// ToTuple returns the tuple for a Debian architecture name.  The pseudo-architectures "all" and "any" have no tuple.
+func ToTuple(arch string) (Tuple, error) {
+	t, ok := load().toTuple[arch]
+	if !ok {
+		return Tuple{}, errors.Errorf("unknown architecture: %q", arch)
+	}
+	return t, nil
+}
+
+// FromTuple returns the Debian architecture name for a tuple.
+func FromTuple(t Tuple) (string, error) {
+	arch, ok := load().fromTuple[t]
+	if !ok {
+		return "", errors.Errorf("no architecture for tuple %v", t)
+	}
+	return arch, nil
+}
+
+// CPUOf returns the cputable entry for an architecture's CPU.
+func CPUOf(arch string) (CPU, error) {
+	t, err := ToTuple(arch)
+	if err != nil {
+		return CPU{}, err
+	}
+	return *load().cpuByName[t.CPU], nil
+}
+
+// Bits returns the pointer width of an architecture, taking the ABI into account (so that e.g. x32 is 32-bit even
+// though its CPU is amd64).
+func Bits(arch string) (int, error) {
+	t, err := ToTuple(arch)
+	if err != nil {
+		return 0, err
+	}
+	if bits, ok := load().abiBits[t.ABI]; ok {
+		return bits, nil
+	}
+	return load().cpuByName[t.CPU].Bits, nil
+}
+
+// GNUTriplet returns the GNU system type for an architecture, e.g. "x86_64-linux-gnu" for "amd64".
+func GNUTriplet(arch string) (string, error) {
+	t, err := ToTuple(arch)
+	if err != nil {
+		return "", err
+	}
+	tbl := load()
+	os, ok := tbl.osByTriplet[t.ABI+"-"+t.LibC+"-"+t.OS]
+	if !ok {
+		return "", errors.Errorf("no GNU system type for architecture %q", arch)
+	}
+	return tbl.cpuByName[t.CPU].GNUName + "-" + os.gnuName, nil
+}
+
+// FromGNUTriplet returns the Debian architecture for a GNU system type, e.g. "armhf" for "arm-linux-gnueabihf".  Like
+// dpkg, this recognizes aliases such as "i586" for the CPU, but not triplets that include a vendor part.
+func FromGNUTriplet(triplet string) (string, error) {
+	gnuCPU, gnuOS, ok := strings.Cut(triplet, "-")
+	if !ok {
+		return "", errors.Errorf("malformed GNU system type: %q", triplet)
+	}
+
+	tbl := load()
+	var cpu *CPU
+	for i := range tbl.cpus {
+		if tbl.cpus[i].gnuRegexp.MatchString(gnuCPU) {
+			cpu = &tbl.cpus[i]
+			break
+		}
+	}
+	var os *osEntry
+	for i := range tbl.oses {
+		if tbl.oses[i].gnuRegexp.MatchString(gnuOS) {
+			os = &tbl.oses[i]
+			break
+		}
+	}
+	if cpu == nil || os == nil {
+		return "", errors.Errorf("unknown GNU system type: %q", triplet)
+	}
+
+	t, err := ParseTuple(os.triplet + "-" + cpu.Name)
+	if err != nil {
+		return "", err
+	}
+	return FromTuple(t)
+}
+
+// IsWildcard reports whether s is an architecture wildcard (such as "any", "linux-any" or "any-arm64") rather than an
+// architecture name.
+func IsWildcard(s string) bool {
+	if s == "any" {
+		return true
+	}
+	for _, part := range strings.Split(s, "-") {
+		if part == "any" {
+			return true
+		}
+	}
+	return false
+}
+
+// WildcardToTuple expands a wildcard to a tuple in which some parts are "any".  Wildcards with fewer than four parts
+// are padded on the left, so "linux-any" becomes "any-any-linux-any" and "any-arm64" becomes "any-any-any-arm64".
+// Architecture names that are not wildcards are converted with ToTuple.
+func WildcardToTuple(s string) (Tuple, error) {
+	if !IsWildcard(s) {
+		return ToTuple(s)
+	}
+	parts := strings.Split(s, "-")
+	if len(parts) > 4 {
+		return Tuple{}, errors.Errorf("malformed architecture wildcard: %q", s)
+	}
+	for len(parts) < 4 {
+		parts = append([]string{"any"}, parts...)
+	}
+	return Tuple{ABI: parts[0], LibC: parts[1], OS: parts[2], CPU: parts[3]}, nil
+}
+
+// Is reports whether the architecture arch is matched by pattern, which may be an architecture name or a wildcard.
+// As in dpkg, "any" matches every architecture (including "all"), and "all" is matched only by itself and by "any".
+func Is(arch, pattern string) bool {
+	if arch == pattern || pattern == "any" {
+		return true
+	}
+	real, err := ToTuple(arch)
+	if err != nil {
+		return false
+	}
+	wild, err := WildcardToTuple(pattern)
+	if err != nil {
+		return false
+	}
+	match := func(w, r string) bool { return w == r || w == "any" }
+	return match(wild.ABI, real.ABI) && match(wild.LibC, real.LibC) && match(wild.OS, real.OS) &&
+		match(wild.CPU, real.CPU)
+}
+
+// IsAny reports whether arch is matched by any of the patterns.
+func IsAny(arch string, patterns []string) bool {
+	for _, p := range patterns {
+		if Is(arch, p) {
+			return true
+		}
+	}
+	return false
+}
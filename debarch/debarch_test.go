@@ -0,0 +1,109 @@
+package debarch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTuples(t *testing.T) {
+	for _, tt := range []struct {
+		arch    string
+		tuple   string
+		triplet string
+		bits    int
+	}{
+		// Expected values are from dpkg-architecture(1).
+		{"amd64", "base-gnu-linux-amd64", "x86_64-linux-gnu", 64},
+		{"i386", "base-gnu-linux-i386", "i686-linux-gnu", 32},
+		{"armhf", "eabihf-gnu-linux-arm", "arm-linux-gnueabihf", 32},
+		{"armel", "eabi-gnu-linux-arm", "arm-linux-gnueabi", 32},
+		{"arm64", "base-gnu-linux-arm64", "aarch64-linux-gnu", 64},
+		{"hurd-i386", "base-gnu-hurd-i386", "i686-gnu", 32},
+		{"kfreebsd-amd64", "base-gnu-kfreebsd-amd64", "x86_64-kfreebsd-gnu", 64},
+		{"x32", "x32-gnu-linux-amd64", "x86_64-linux-gnux32", 32},
+		{"musl-linux-amd64", "base-musl-linux-amd64", "x86_64-linux-musl", 64},
+		{"mips64el", "abi64-gnu-linux-mips64el", "mips64el-linux-gnuabi64", 64},
+		{"powerpcspe", "spe-gnu-linux-powerpc", "powerpc-linux-gnuspe", 32},
+		{"ppc64el", "base-gnu-linux-ppc64el", "powerpc64le-linux-gnu", 64},
+	} {
+		tuple, err := ToTuple(tt.arch)
+		if !assert.NoError(t, err, tt.arch) {
+			continue
+		}
+		assert.Equal(t, tt.tuple, tuple.String(), tt.arch)
+
+		arch, err := FromTuple(tuple)
+		if assert.NoError(t, err, tt.arch) {
+			assert.Equal(t, tt.arch, arch)
+		}
+
+		triplet, err := GNUTriplet(tt.arch)
+		if assert.NoError(t, err, tt.arch) {
+			assert.Equal(t, tt.triplet, triplet)
+		}
+		arch, err = FromGNUTriplet(tt.triplet)
+		if assert.NoError(t, err, tt.triplet) {
+			assert.Equal(t, tt.arch, arch)
+		}
+
+		bits, err := Bits(tt.arch)
+		if assert.NoError(t, err, tt.arch) {
+			assert.Equal(t, tt.bits, bits, tt.arch)
+		}
+	}
+
+	arch, err := FromGNUTriplet("i586-linux-gnu")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "i386", arch)
+	}
+	cpu, err := CPUOf("s390x")
+	if assert.NoError(t, err) {
+		assert.True(t, cpu.BigEndian)
+		assert.Equal(t, 64, cpu.Bits)
+	}
+
+	for _, bad := range []string{"all", "any", "bogus", ""} {
+		_, err := ToTuple(bad)
+		assert.Error(t, err, bad)
+	}
+	_, err = FromGNUTriplet("x86_64-pc-linux-gnu")
+	assert.Error(t, err)
+	assert.Contains(t, Known(), "riscv64")
+}
+
+func TestIs(t *testing.T) {
+	for _, tt := range []struct {
+		arch     string
+		pattern  string
+		expected bool
+	}{
+		{"amd64", "amd64", true},
+		{"amd64", "i386", false},
+		{"amd64", "any", true},
+		{"all", "any", true},
+		{"all", "all", true},
+		{"amd64", "all", false},
+		{"amd64", "linux-any", true},
+		{"hurd-i386", "linux-any", false},
+		{"hurd-i386", "hurd-any", true},
+		{"arm64", "any-arm64", true},
+		{"armhf", "any-arm", true},
+		{"armhf", "any-arm64", false},
+		{"x32", "any-amd64", true},
+		{"x32", "x32-any-any-any", true},
+		{"musl-linux-amd64", "gnu-any-any", false},
+		{"amd64", "gnu-any-any", true},
+		{"amd64", "gnu-linux-any", true},
+		{"kfreebsd-amd64", "any-any-kfreebsd-any", true},
+		{"bogus", "linux-any", false},
+		{"amd64", "any-any-any-any-any", false},
+	} {
+		assert.Equal(t, tt.expected, Is(tt.arch, tt.pattern), "%q is %q", tt.arch, tt.pattern)
+	}
+
+	assert.True(t, IsWildcard("linux-any"))
+	assert.False(t, IsWildcard("amd64"))
+	assert.True(t, IsAny("i386", []string{"amd64", "any-i386"}))
+	assert.False(t, IsAny("i386", nil))
+}
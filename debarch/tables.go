@@ -0,0 +1,120 @@
+package debarch
+
+// The tables below are copied from the data files shipped with dpkg 1.21.22 (/usr/share/dpkg/*table), and are parsed
+// the same way that dpkg parses them: where two entries would describe the same architecture, the first one wins.
+
+// cputable columns: Debian CPU name, GNU CPU name, GNU CPU regular expression, bits, endianness.
+const cputable = `
+alpha		alpha		alpha.*			64	little
+amd64		x86_64		(amd64|x86_64)		64	little
+arc		arc		arc			32	little
+armeb		armeb		arm.*b			32	big
+arm		arm		arm.*			32	little
+arm64		aarch64		aarch64			64	little
+avr32		avr32		avr32			32	big
+hppa		hppa		hppa.*			32	big
+loong64		loongarch64	loongarch64		64	little
+i386		i686		(i[34567]86|pentium)	32	little
+ia64		ia64		ia64			64	little
+m32r		m32r		m32r			32	big
+m68k		m68k		m68k			32	big
+mips		mips		mips(eb)?		32	big
+mipsel		mipsel		mipsel			32	little
+mipsr6		mipsisa32r6	mipsisa32r6		32	big
+mipsr6el	mipsisa32r6el	mipsisa32r6el		32	little
+mips64		mips64		mips64			64	big
+mips64el	mips64el	mips64el		64	little
+mips64r6	mipsisa64r6	mipsisa64r6		64	big
+mips64r6el	mipsisa64r6el	mipsisa64r6el		64	little
+nios2		nios2		nios2			32	little
+or1k		or1k		or1k			32	big
+powerpc		powerpc		(powerpc|ppc)		32	big
+powerpcel	powerpcle	powerpcle		32	little
+ppc64		powerpc64	(powerpc|ppc)64		64	big
+ppc64el		powerpc64le	powerpc64le		64	little
+riscv64		riscv64		riscv64			64	little
+s390		s390		s390			32	big
+s390x		s390x		s390x			64	big
+sh3		sh3		sh3			32	little
+sh3eb		sh3eb		sh3eb			32	big
+sh4		sh4		sh4			32	little
+sh4eb		sh4eb		sh4eb			32	big
+sparc		sparc		sparc			32	big
+sparc64		sparc64		sparc64			64	big
+tilegx		tilegx		tilegx			64	little
+`
+
+// ostable columns: Debian ABI-libc-OS triplet, GNU system name, GNU system regular expression.
+const ostable = `
+eabi-uclibc-linux	linux-uclibceabi	linux[^-]*-uclibceabi
+base-uclibc-linux	linux-uclibc		linux[^-]*-uclibc
+eabihf-musl-linux	linux-musleabihf	linux[^-]*-musleabihf
+base-musl-linux		linux-musl		linux[^-]*-musl
+eabihf-gnu-linux	linux-gnueabihf		linux[^-]*-gnueabihf
+eabi-gnu-linux		linux-gnueabi		linux[^-]*-gnueabi
+abin32-gnu-linux	linux-gnuabin32		linux[^-]*-gnuabin32
+abi64-gnu-linux		linux-gnuabi64		linux[^-]*-gnuabi64
+spe-gnu-linux		linux-gnuspe		linux[^-]*-gnuspe
+x32-gnu-linux		linux-gnux32		linux[^-]*-gnux32
+ilp32-gnu-linux		linux-gnu_ilp32		linux[^-]*-gnu_ilp32
+base-gnu-linux		linux-gnu		linux[^-]*(-gnu.*)?
+eabihf-gnu-kfreebsd	kfreebsd-gnueabihf	kfreebsd[^-]*-gnueabihf
+base-gnu-kfreebsd	kfreebsd-gnu		kfreebsd[^-]*(-gnu.*)?
+base-gnu-knetbsd	knetbsd-gnu		knetbsd[^-]*(-gnu.*)?
+base-gnu-kopensolaris	kopensolaris-gnu	kopensolaris[^-]*(-gnu.*)?
+base-gnu-hurd		gnu			gnu[^-]*
+base-bsd-darwin		darwin			darwin[^-]*
+base-bsd-dragonflybsd	dragonflybsd		dragonfly[^-]*
+base-bsd-freebsd	freebsd			freebsd[^-]*
+base-bsd-netbsd		netbsd			netbsd[^-]*
+base-bsd-openbsd	openbsd			openbsd[^-]*
+base-sysv-aix		aix			aix[^-]*
+base-sysv-solaris	solaris			solaris[^-]*
+eabi-uclibc-uclinux	uclinux-uclibceabi	uclinux[^-]*-uclibceabi
+base-uclibc-uclinux	uclinux-uclibc		uclinux[^-]*(-uclibc.*)?
+base-tos-mint		mint			mint[^-]*
+`
+
+// tupletable columns: Debian tuple, Debian architecture name.  "<cpu>" stands for each CPU from cputable in turn.
+const tupletable = `
+eabi-uclibc-linux-arm		uclibc-linux-armel
+base-uclibc-linux-<cpu>		uclibc-linux-<cpu>
+eabihf-musl-linux-arm		musl-linux-armhf
+base-musl-linux-<cpu>		musl-linux-<cpu>
+ilp32-gnu-linux-arm64		arm64ilp32
+eabihf-gnu-linux-arm		armhf
+eabi-gnu-linux-arm		armel
+abin32-gnu-linux-mips64r6el	mipsn32r6el
+abin32-gnu-linux-mips64r6	mipsn32r6
+abin32-gnu-linux-mips64el	mipsn32el
+abin32-gnu-linux-mips64		mipsn32
+abi64-gnu-linux-mips64r6el	mips64r6el
+abi64-gnu-linux-mips64r6	mips64r6
+abi64-gnu-linux-mips64el	mips64el
+abi64-gnu-linux-mips64		mips64
+spe-gnu-linux-powerpc		powerpcspe
+x32-gnu-linux-amd64		x32
+base-gnu-linux-<cpu>		<cpu>
+eabihf-gnu-kfreebsd-arm		kfreebsd-armhf
+base-gnu-kfreebsd-<cpu>		kfreebsd-<cpu>
+base-gnu-knetbsd-<cpu>		knetbsd-<cpu>
+base-gnu-kopensolaris-<cpu>	kopensolaris-<cpu>
+base-gnu-hurd-<cpu>		hurd-<cpu>
+base-bsd-dragonflybsd-<cpu>	dragonflybsd-<cpu>
+base-bsd-freebsd-<cpu>		freebsd-<cpu>
+base-bsd-openbsd-<cpu>		openbsd-<cpu>
+base-bsd-netbsd-<cpu>		netbsd-<cpu>
+base-bsd-darwin-<cpu>		darwin-<cpu>
+base-sysv-aix-<cpu>		aix-<cpu>
+base-sysv-solaris-<cpu>		solaris-<cpu>
+eabi-uclibc-uclinux-arm		uclinux-armel
+base-uclibc-uclinux-<cpu>	uclinux-<cpu>
+base-tos-mint-m68k		mint-m68k
+`
+
+// abitable columns: Debian ABI name, bits; the bit width overrides the one from cputable.
+const abitable = `
+abin32		32
+ilp32		32
+x32		32
+`
@@ -0,0 +1,95 @@
+package debcontrol
+
+import (
+	"github.com/kelleyk/godebian/debrelation"
+	"github.com/kelleyk/godebian/debversion"
+	"github.com/pkg/errors"
+)
+
+// Values of the Multi-Arch field.
+//
+// Ref.: https://wiki.ubuntu.com/MultiarchSpec
+const (
+	MultiArchNo      = "no"
+	MultiArchSame    = "same"
+	MultiArchForeign = "foreign"
+	MultiArchAllowed = "allowed"
+)
+
+// BinaryPackage holds the fields of a binary package's control file (or of a stanza in a Packages index) that
+// describe its identity and its relationships to other packages.
+type BinaryPackage struct {
+	Package      string
+	Version      debversion.DebianVersion
+	Architecture string
+	// MultiArch is the value of the Multi-Arch field, or MultiArchNo if the field is absent.
+	MultiArch string
+	Essential bool
+
+	Depends    debrelation.Relations
+	PreDepends debrelation.Relations
+	Recommends debrelation.Relations
+	Suggests   debrelation.Relations
+	Enhances   debrelation.Relations
+	Conflicts  debrelation.Relations
+	Breaks     debrelation.Relations
+	Replaces   debrelation.Relations
+	Provides   debrelation.Relations
+
+	// Paragraph is the paragraph that the package was parsed from; it holds the fields not listed above.
+	Paragraph Paragraph
+}
+
+// String identifies the package by name, version and architecture (e.g. "libc6_2.36-9_amd64").
+func (b *BinaryPackage) String() string {
+	return b.Package + "_" + b.Version.String() + "_" + b.Architecture
+}
+
+// ParseBinaryPackage builds a BinaryPackage from a control paragraph.  The Package, Version and Architecture fields are
+// required.
+func ParseBinaryPackage(p Paragraph) (*BinaryPackage, error) {
+	b := &BinaryPackage{
+		Package:      p.Value("Package"),
+		Architecture: p.Value("Architecture"),
+		MultiArch:    p.Value("Multi-Arch"),
+		Essential:    p.Value("Essential") == "yes",
+		Paragraph:    p,
+	}
+	for _, name := range []string{"Package", "Version", "Architecture"} {
+		if _, ok := p.Get(name); !ok {
+			return nil, errors.Errorf("package is missing the %v field", name)
+		}
+	}
+	if b.MultiArch == "" {
+		b.MultiArch = MultiArchNo
+	}
+
+	v, err := debversion.FromString(p.Value("Version"))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse version of %v", b.Package)
+	}
+	b.Version = v
+
+	for _, f := range []struct {
+		name string
+		dst  *debrelation.Relations
+	}{
+		{"Depends", &b.Depends},
+		{"Pre-Depends", &b.PreDepends},
+		{"Recommends", &b.Recommends},
+		{"Suggests", &b.Suggests},
+		{"Enhances", &b.Enhances},
+		{"Conflicts", &b.Conflicts},
+		{"Breaks", &b.Breaks},
+		{"Replaces", &b.Replaces},
+		{"Provides", &b.Provides},
+	} {
+		rs, err := debrelation.Parse(p.Value(f.name))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse %v field of %v", f.name, b.Package)
+		}
+		*f.dst = rs
+	}
+
+	return b, nil
+}
@@ -0,0 +1,189 @@
+package debcontrol
+
+import (
+	"bufio"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Ref.: https://www.debian.org/doc/debian-policy/ch-controlfields.html#syntax-of-control-files
+//       deb822(5)
+
+type Field struct {
+	Name string
+	// Value is the field's value with the whitespace that follows the colon removed.  The lines of a multiline value
+	// are separated by '\n', and the single leading space that marks each continuation line is removed.
+	Value string
+}
+
+// Paragraph is an ordered list of fields, also known as a stanza.  Field names are case-insensitive.
+type Paragraph struct {
+	Fields []Field
+}
+
+func (p Paragraph) index(name string) int {
+	for i, f := range p.Fields {
+		if strings.EqualFold(f.Name, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Get returns the value of the named field and whether or not it is present.
+func (p Paragraph) Get(name string) (string, bool) {
+	if i := p.index(name); i != -1 {
+		return p.Fields[i].Value, true
+	}
+	return "", false
+}
+
+// Value returns the value of the named field, or the empty string if it is not present.
+func (p Paragraph) Value(name string) string {
+	v, _ := p.Get(name)
+	return v
+}
+
+// Set replaces the value of the named field, or appends the field if it is not already present.
+func (p *Paragraph) Set(name, value string) {
+	if i := p.index(name); i != -1 {
+		p.Fields[i].Value = value
+		return
+	}
+	p.Fields = append(p.Fields, Field{Name: name, Value: value})
+}
+
+// Delete removes the named field, if it is present.
+func (p *Paragraph) Delete(name string) {
+	if i := p.index(name); i != -1 {
+		p.Fields = append(p.Fields[:i], p.Fields[i+1:]...)
+	}
+}
+
+// String formats the paragraph in control-file syntax, without a trailing blank line.
+func (p Paragraph) String() string {
+	var b strings.Builder
+	for _, f := range p.Fields {
+		b.WriteString(f.Name)
+		b.WriteString(":")
+		for i, line := range strings.Split(f.Value, "\n") {
+			if i > 0 {
+				b.WriteString("\n")
+			}
+			if line != "" || i > 0 {
+				b.WriteString(" ")
+			}
+			b.WriteString(line)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// Reader reads paragraphs one at a time, so that large files (such as repository indices) need not be held in memory.
+type Reader struct {
+	r      *bufio.Reader
+	lineNo int
+}
+
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReader(r)}
+}
+
+func (r *Reader) readLine() (string, error) {
+	line, err := r.r.ReadString('\n')
+	if err == io.EOF && line != "" {
+		err = nil
+	}
+	if err != nil {
+		return "", err
+	}
+	r.lineNo++
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// Next returns the next paragraph.  It returns io.EOF when there are no more paragraphs.  Comment lines (those that
+// begin with '#') are skipped.
+func (r *Reader) Next() (Paragraph, error) {
+	var p Paragraph
+	seen := make(map[string]bool)
+
+	for {
+		line, err := r.readLine()
+		if err == io.EOF {
+			if len(p.Fields) == 0 {
+				return p, io.EOF
+			}
+			return p, nil
+		}
+		if err != nil {
+			return p, err
+		}
+
+		switch {
+		case strings.HasPrefix(line, "#"):
+			continue
+
+		case strings.TrimSpace(line) == "":
+			if len(p.Fields) > 0 {
+				return p, nil
+			}
+
+		case line[0] == ' ' || line[0] == '\t':
+			if len(p.Fields) == 0 {
+				return p, errors.Errorf("line %d: continuation line outside of a field", r.lineNo)
+			}
+			p.Fields[len(p.Fields)-1].Value += "\n" + line[1:]
+
+		default:
+			i := strings.Index(line, ":")
+			if i <= 0 {
+				return p, errors.Errorf("line %d: expected a field: %q", r.lineNo, line)
+			}
+			name := line[:i]
+			if strings.ContainsAny(name, " \t") || name[0] == '-' {
+				return p, errors.Errorf("line %d: malformed field name: %q", r.lineNo, name)
+			}
+			key := strings.ToLower(name)
+			if seen[key] {
+				return p, errors.Errorf("line %d: duplicate field: %q", r.lineNo, name)
+			}
+			seen[key] = true
+			p.Fields = append(p.Fields, Field{Name: name, Value: strings.TrimSpace(line[i+1:])})
+		}
+	}
+}
+
+// ParseParagraphs reads all of the paragraphs from r.
+func ParseParagraphs(r io.Reader) ([]Paragraph, error) {
+	var ps []Paragraph
+	cr := NewReader(r)
+	for {
+		p, err := cr.Next()
+		if err == io.EOF {
+			return ps, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		ps = append(ps, p)
+	}
+}
+
+// SplitDescription splits the value of a Description-style field into its synopsis (the first line) and its extended
+// description.  In the extended description, lines consisting of a single '.' become empty lines.
+func SplitDescription(value string) (string, string) {
+	synopsis, rest, _ := strings.Cut(value, "\n")
+	if rest == "" {
+		return synopsis, ""
+	}
+	lines := strings.Split(rest, "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "." {
+			lines[i] = ""
+		}
+	}
+	return synopsis, strings.Join(lines, "\n")
+}
@@ -0,0 +1,89 @@
+package debcontrol
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testParagraphs = `# leading comment
+Package: foo
+Version: 1.0-1
+Description: a foo
+ Foo is a thing.
+ .
+ It does things.
+
+ 
+Package: bar
+depends:libc6
+`
+
+func TestReader(t *testing.T) {
+	r := NewReader(strings.NewReader(testParagraphs))
+
+	p, err := r.Next()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []Field{
+		{Name: "Package", Value: "foo"},
+		{Name: "Version", Value: "1.0-1"},
+		{Name: "Description", Value: "a foo\nFoo is a thing.\n.\nIt does things."},
+	}, p.Fields)
+
+	p, err = r.Next()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "bar", p.Value("package"))
+	v, ok := p.Get("Depends")
+	assert.True(t, ok)
+	assert.Equal(t, "libc6", v)
+	_, ok = p.Get("Version")
+	assert.False(t, ok)
+
+	_, err = r.Next()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestReaderErrors(t *testing.T) {
+	for _, s := range []string{
+		" continuation\n",
+		"Package: foo\nPackage: bar\n",
+		"Package foo\n",
+		"Bad Name: foo\n",
+	} {
+		_, err := ParseParagraphs(strings.NewReader(s))
+		assert.Error(t, err, s)
+	}
+}
+
+func TestParagraphString(t *testing.T) {
+	ps, err := ParseParagraphs(strings.NewReader(testParagraphs))
+	if !assert.NoError(t, err) || !assert.Len(t, ps, 2) {
+		return
+	}
+	assert.Equal(t, "Package: foo\nVersion: 1.0-1\nDescription: a foo\n Foo is a thing.\n .\n It does things.\n",
+		ps[0].String())
+
+	p := Paragraph{}
+	p.Set("Files", "\nabc 1 foo.tar.gz")
+	p.Set("Package", "foo")
+	p.Set("files", "\ndef 2 foo.dsc")
+	assert.Equal(t, "Files:\n def 2 foo.dsc\nPackage: foo\n", p.String())
+	p.Delete("FILES")
+	assert.Equal(t, "Package: foo\n", p.String())
+}
+
+func TestSplitDescription(t *testing.T) {
+	short, long := SplitDescription("a foo\nFoo is a thing.\n.\nIt does things.")
+	assert.Equal(t, "a foo", short)
+	assert.Equal(t, "Foo is a thing.\n\nIt does things.", long)
+
+	short, long = SplitDescription("just a synopsis")
+	assert.Equal(t, "just a synopsis", short)
+	assert.Equal(t, "", long)
+}
@@ -0,0 +1,66 @@
+package debcontrol
+
+import (
+	"github.com/kelleyk/godebian/debrelation"
+	"github.com/pkg/errors"
+)
+
+// BuildRelations holds the build relationship fields of a source package after reduction for a particular build.
+type BuildRelations struct {
+	Context debrelation.BuildContext
+
+	Depends        debrelation.Relations
+	DependsArch    debrelation.Relations
+	DependsIndep   debrelation.Relations
+	Conflicts      debrelation.Relations
+	ConflictsArch  debrelation.Relations
+	ConflictsIndep debrelation.Relations
+}
+
+// AllDepends returns every build dependency that applies.
+func (b *BuildRelations) AllDepends() debrelation.Relations {
+	return concatRelations(b.Depends, b.DependsArch, b.DependsIndep)
+}
+
+// AllConflicts returns every build conflict that applies.
+func (b *BuildRelations) AllConflicts() debrelation.Relations {
+	return concatRelations(b.Conflicts, b.ConflictsArch, b.ConflictsIndep)
+}
+
+func concatRelations(rss ...debrelation.Relations) debrelation.Relations {
+	all := debrelation.Relations{}
+	for _, rs := range rss {
+		all = append(all, rs...)
+	}
+	return all
+}
+
+// ReduceBuildRelations parses and reduces the build relationship fields of a source package stanza (from a .dsc file,
+// a Sources index or debian/control).  The "-Arch" fields are only included when arch is set (i.e. when
+// architecture-dependent binary packages are being built) and the "-Indep" fields only when indep is set, which
+// mirrors the -B and -A options of dpkg-checkbuilddeps(1).
+func ReduceBuildRelations(p Paragraph, ctx debrelation.BuildContext, arch, indep bool) (*BuildRelations, error) {
+	b := &BuildRelations{Context: ctx}
+	for _, f := range []struct {
+		name    string
+		dst     *debrelation.Relations
+		include bool
+	}{
+		{"Build-Depends", &b.Depends, true},
+		{"Build-Depends-Arch", &b.DependsArch, arch},
+		{"Build-Depends-Indep", &b.DependsIndep, indep},
+		{"Build-Conflicts", &b.Conflicts, true},
+		{"Build-Conflicts-Arch", &b.ConflictsArch, arch},
+		{"Build-Conflicts-Indep", &b.ConflictsIndep, indep},
+	} {
+		if !f.include {
+			continue
+		}
+		rs, err := debrelation.Parse(p.Value(f.name))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse %v field", f.name)
+		}
+		*f.dst = rs.Reduce(ctx)
+	}
+	return b, nil
+}
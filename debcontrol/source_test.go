@@ -0,0 +1,50 @@
+package debcontrol
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kelleyk/godebian/debrelation"
+	"github.com/stretchr/testify/assert"
+)
+
+const testSource = `Source: foo
+Build-Depends: debhelper-compat (= 13),
+ libssl-dev,
+ libsystemd-dev [linux-any],
+ python3-pytest <!nocheck>
+Build-Depends-Arch: gcc-multilib [amd64]
+Build-Depends-Indep: sphinx <!nodoc>
+Build-Conflicts: libfoo-old-dev
+Build-Conflicts-Indep: texlive-bogus [i386]
+`
+
+func TestReduceBuildRelations(t *testing.T) {
+	p, err := NewReader(strings.NewReader(testSource)).Next()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	ctx := debrelation.BuildContext{HostArch: "arm64", BuildArch: "amd64", Profiles: []string{"nocheck", "cross"}}
+	b, err := ReduceBuildRelations(p, ctx, true, false)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, ctx, b.Context)
+	assert.Equal(t, "debhelper-compat (= 13), libssl-dev, libsystemd-dev", b.Depends.String())
+	assert.Equal(t, "", b.DependsArch.String())
+	assert.Nil(t, b.DependsIndep)
+	assert.Equal(t, "debhelper-compat (= 13), libssl-dev, libsystemd-dev", b.AllDepends().String())
+	assert.Equal(t, "libfoo-old-dev", b.AllConflicts().String())
+
+	b, err = ReduceBuildRelations(p, debrelation.BuildContext{HostArch: "i386", BuildArch: "i386"}, true, true)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "debhelper-compat (= 13), libssl-dev, libsystemd-dev, python3-pytest, sphinx",
+			b.AllDepends().String())
+		assert.Equal(t, "libfoo-old-dev, texlive-bogus", b.AllConflicts().String())
+	}
+
+	p.Set("Build-Depends", "foo [amd64")
+	_, err = ReduceBuildRelations(p, ctx, true, true)
+	assert.Error(t, err)
+}
@@ -0,0 +1,113 @@
+package debfile
+
+import (
+	"archive/tar"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"hash"
+	"io"
+)
+
+// Checksums holds the size and hex-encoded digests of a file, as used in checksum control files and in repository
+// indices.
+type Checksums struct {
+	Size   int64
+	MD5    string
+	SHA1   string
+	SHA256 string
+	SHA512 string
+}
+
+// Checksummer is an io.Writer that computes Checksums for everything written to it, so that checksums can be computed
+// while a file is being streamed elsewhere (e.g. with io.TeeReader or io.MultiWriter).
+type Checksummer struct {
+	size   int64
+	md5    hash.Hash
+	sha1   hash.Hash
+	sha256 hash.Hash
+	sha512 hash.Hash
+}
+
+func NewChecksummer() *Checksummer {
+	return &Checksummer{
+		md5:    md5.New(),
+		sha1:   sha1.New(),
+		sha256: sha256.New(),
+		sha512: sha512.New(),
+	}
+}
+
+func (c *Checksummer) Write(p []byte) (int, error) {
+	// N.B.: hash.Hash.Write never returns an error.
+	c.md5.Write(p)
+	c.sha1.Write(p)
+	c.sha256.Write(p)
+	c.sha512.Write(p)
+	c.size += int64(len(p))
+	return len(p), nil
+}
+
+// Sum returns the checksums of everything written so far.  It does not change the underlying state, so writing may
+// continue afterwards.
+func (c *Checksummer) Sum() Checksums {
+	return Checksums{
+		Size:   c.size,
+		MD5:    hex.EncodeToString(c.md5.Sum(nil)),
+		SHA1:   hex.EncodeToString(c.sha1.Sum(nil)),
+		SHA256: hex.EncodeToString(c.sha256.Sum(nil)),
+		SHA512: hex.EncodeToString(c.sha512.Sum(nil)),
+	}
+}
+
+// ComputeChecksums reads r to EOF and returns the checksums of its contents.
+func ComputeChecksums(r io.Reader) (Checksums, error) {
+	c := NewChecksummer()
+	if _, err := io.Copy(c, r); err != nil {
+		return Checksums{}, err
+	}
+	return c.Sum(), nil
+}
+
+// Checksums returns the checksums of the entry's contents.  Entries other than regular files have no contents, and so
+// have the checksums of the empty string.
+func (e *TarballEntry) Checksums() Checksums {
+	c := NewChecksummer()
+	c.Write(e.Data)
+	return c.Sum()
+}
+
+// Checksums returns the checksums of each regular file in the tarball, keyed by entry name.
+func (t Tarball) Checksums() map[string]Checksums {
+	m := make(map[string]Checksums)
+	for name, e := range t.Contents {
+		if e.IsReg() {
+			m[name] = e.Checksums()
+		}
+	}
+	return m
+}
+
+// InstalledSize estimates the disk space, in KiB, that the tarball's contents occupy once installed.  This is the
+// value that dpkg-gencontrol(1) computes for the Installed-Size field: regular files and symlinks contribute their
+// size rounded up to a whole KiB (hard links to the same file are only counted once), and every other kind of entry,
+// including directories, contributes 1 KiB.
+func (t Tarball) InstalledSize() int64 {
+	var total int64
+	for _, e := range t.Contents {
+		switch {
+		case e.IsReg():
+			total += (e.Header.Size + 1023) / 1024
+		case e.IsSymlink():
+			// dpkg-gencontrol uses the lstat(2) size of the link, which is the length of its target.
+			total += (int64(len(e.Header.Linkname)) + 1023) / 1024
+		case e.Header.Typeflag == tar.TypeLink:
+			// The file that this links to has already been counted.
+		default:
+			total++
+		}
+	}
+	return total
+}
@@ -0,0 +1,48 @@
+package debfile
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeChecksums(t *testing.T) {
+	c, err := ComputeChecksums(strings.NewReader("hello"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, Checksums{
+		Size:   5,
+		MD5:    "5d41402abc4b2a76b9719d911017c592",
+		SHA1:   "aaf4c61ddcc5e8a2dabede0f3b482cd9aea9434d",
+		SHA256: "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824",
+		SHA512: "9b71d224bd62f3785d96d46ad3ea3d73319bfbc2890caadae2dff72519673ca7" +
+			"2323c3d99ba5c11d7c7acc6e14b8c5da0c4663475c2e5c3adef46f73bcdec043",
+	}, c)
+
+	// Sum must not disturb the running state.
+	cs := NewChecksummer()
+	cs.Write([]byte("hel"))
+	cs.Sum()
+	cs.Write([]byte("lo"))
+	assert.Equal(t, c, cs.Sum())
+}
+
+func TestTarballChecksumsAndInstalledSize(t *testing.T) {
+	d := loadTestDeb(t, []testEntry{{"/", ""}}, []testEntry{
+		{"/", ""}, {"/usr/", ""}, {"/usr/bin/", ""},
+		{"/usr/bin/foo", "hello"},
+		{"/usr/bin/big", strings.Repeat("x", 2049)},
+		{"/usr/bin/empty", ""},
+		{"/usr/bin/baz -> foo", ""},
+	})
+
+	sums := d.Data().Checksums()
+	assert.Len(t, sums, 3)
+	assert.Equal(t, "5d41402abc4b2a76b9719d911017c592", sums["/usr/bin/foo"].MD5)
+	assert.Equal(t, int64(2049), sums["/usr/bin/big"].Size)
+
+	// 3 directories + foo (1) + big (3) + empty (0) + symlink (1)
+	assert.Equal(t, int64(8), d.Data().InstalledSize())
+}
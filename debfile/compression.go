@@ -0,0 +1,176 @@
+package debfile
+
+import (
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"io"
+	"os/exec"
+	"strings"
+
+	"github.com/lxq/lzma"
+	"github.com/pkg/errors"
+	xz "github.com/smira/go-xz"
+)
+
+// Decompress returns a reader that decompresses r according to the compression format implied by a file extension:
+// ".xz", ".gz", ".bz2", ".lz" or ".zst" (as used for the members of .deb files and for repository indices).  An empty
+// extension means that r is not compressed.
+//
+// Like xz, zstd is decompressed by running the external program of the same name.  Readers that do so implement
+// io.Closer, and should be closed if they are not read to the end.
+func Decompress(r io.Reader, ext string) (io.Reader, error) {
+	switch ext {
+	case "":
+		return r, nil
+	case ".xz":
+		xr, err := xz.NewReader(r)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create xz reader")
+		}
+		return xr, nil
+	case ".gz":
+		gr, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create gzip reader")
+		}
+		return gr, nil
+	case ".bz2":
+		return bzip2.NewReader(r), nil
+	case ".lz":
+		return lzma.NewReader(r), nil
+	case ".zst":
+		zr, err := newExecReader(r, "zstd", "--decompress", "--stdout")
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create zstd reader")
+		}
+		return zr, nil
+	default:
+		return nil, errors.Errorf("unknown compression extension: %q", ext)
+	}
+}
+
+// execReader reads the output of an external program that is fed from a reader.
+type execReader struct {
+	cmd    *exec.Cmd
+	stdout io.ReadCloser
+	stderr bytes.Buffer
+	done   bool
+}
+
+func newExecReader(r io.Reader, name string, args ...string) (*execReader, error) {
+	er := &execReader{cmd: exec.Command(name, args...)}
+	er.cmd.Stdin = r
+	er.cmd.Stderr = &er.stderr
+
+	var err error
+	if er.stdout, err = er.cmd.StdoutPipe(); err != nil {
+		return nil, err
+	}
+	if err := er.cmd.Start(); err != nil {
+		return nil, err
+	}
+	return er, nil
+}
+
+func (er *execReader) Read(p []byte) (int, error) {
+	if er.done {
+		return 0, io.EOF
+	}
+	n, err := er.stdout.Read(p)
+	if err == io.EOF {
+		// Make sure that the program was happy with its input before reporting that we are done.
+		er.done = true
+		if werr := er.cmd.Wait(); werr != nil {
+			return n, errors.Wrapf(werr, "%v: %s", er.cmd.Path, strings.TrimSpace(er.stderr.String()))
+		}
+	}
+	return n, err
+}
+
+// Close stops the program if it is still running.
+func (er *execReader) Close() error {
+	if er.done {
+		return nil
+	}
+	er.done = true
+	_ = er.cmd.Process.Kill()
+	_ = er.cmd.Wait()
+	return nil
+}
+
+// Compress returns a writer that compresses what is written to it into w, in the format implied by a file extension:
+// ".gz", ".xz" or ".zst".  An empty extension means no compression.  The writer must be closed to flush the compressed
+// data; closing it does not close w.
+//
+// Like Decompress, Compress runs the external program of the same name for xz and zstd.
+func Compress(w io.Writer, ext string) (io.WriteCloser, error) {
+	switch ext {
+	case "":
+		return nopWriteCloser{w}, nil
+	case ".gz":
+		// N.B.: The header has no name or modification time, so the output depends only on the input.
+		gw, err := gzip.NewWriterLevel(w, gzip.BestCompression)
+		if err != nil {
+			return nil, err
+		}
+		return gw, nil
+	case ".xz":
+		xw, err := newExecWriter(w, "xz", "--compress", "--stdout")
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create xz writer")
+		}
+		return xw, nil
+	case ".zst":
+		zw, err := newExecWriter(w, "zstd", "--compress", "--stdout", "--quiet")
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create zstd writer")
+		}
+		return zw, nil
+	default:
+		return nil, errors.Errorf("unsupported compression extension: %q", ext)
+	}
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+// execWriter feeds an external program, whose output goes to a writer.
+type execWriter struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stderr bytes.Buffer
+}
+
+func newExecWriter(w io.Writer, name string, args ...string) (*execWriter, error) {
+	ew := &execWriter{cmd: exec.Command(name, args...)}
+	ew.cmd.Stdout = w
+	ew.cmd.Stderr = &ew.stderr
+
+	var err error
+	if ew.stdin, err = ew.cmd.StdinPipe(); err != nil {
+		return nil, err
+	}
+	if err := ew.cmd.Start(); err != nil {
+		return nil, err
+	}
+	return ew, nil
+}
+
+func (ew *execWriter) Write(p []byte) (int, error) {
+	return ew.stdin.Write(p)
+}
+
+// Close signals the end of the input and waits for the program to finish writing its output.
+func (ew *execWriter) Close() error {
+	ew.stdin.Close()
+	if err := ew.cmd.Wait(); err != nil {
+		return errors.Wrapf(err, "%v: %s", ew.cmd.Path, strings.TrimSpace(ew.stderr.String()))
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package debfile
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecompress(t *testing.T) {
+	const text = "Package: hello\nVersion: 2.10-3\n"
+
+	var gz bytes.Buffer
+	w := gzip.NewWriter(&gz)
+	w.Write([]byte(text))
+	w.Close()
+
+	compressed := map[string][]byte{"": []byte(text), ".gz": gz.Bytes()}
+	for ext, program := range map[string]string{".xz": "xz", ".bz2": "bzip2", ".zst": "zstd"} {
+		if _, err := exec.LookPath(program); err != nil {
+			t.Logf("skipping %v: %v", ext, err)
+			continue
+		}
+		cmd := exec.Command(program, "--stdout")
+		cmd.Stdin = bytes.NewReader([]byte(text))
+		out, err := cmd.Output()
+		if err != nil {
+			t.Fatal(err)
+		}
+		compressed[ext] = out
+	}
+
+	for ext, data := range compressed {
+		r, err := Decompress(bytes.NewReader(data), ext)
+		if !assert.Nil(t, err, ext) {
+			continue
+		}
+		out, err := io.ReadAll(r)
+		assert.Nil(t, err, ext)
+		assert.Equal(t, text, string(out), ext)
+	}
+
+	_, err := Decompress(bytes.NewReader(nil), ".rar")
+	assert.NotNil(t, err)
+}
+
+func TestDecompressCorrupt(t *testing.T) {
+	if _, err := exec.LookPath("zstd"); err != nil {
+		t.Skip(err)
+	}
+	r, err := Decompress(bytes.NewReader([]byte("not zstd")), ".zst")
+	if !assert.Nil(t, err) {
+		return
+	}
+	_, err = io.ReadAll(r)
+	assert.NotNil(t, err)
+}
+
+func TestCompress(t *testing.T) {
+	const text = "Package: hello\nVersion: 2.10-3\n"
+
+	for ext, program := range map[string]string{"": "", ".gz": "", ".xz": "xz", ".zst": "zstd"} {
+		if program != "" {
+			if _, err := exec.LookPath(program); err != nil {
+				t.Logf("skipping %v: %v", ext, err)
+				continue
+			}
+		}
+		var b bytes.Buffer
+		w, err := Compress(&b, ext)
+		if !assert.Nil(t, err, ext) {
+			continue
+		}
+		w.Write([]byte(text))
+		if !assert.Nil(t, w.Close(), ext) {
+			continue
+		}
+
+		r, err := Decompress(&b, ext)
+		if !assert.Nil(t, err, ext) {
+			continue
+		}
+		out, err := io.ReadAll(r)
+		assert.Nil(t, err, ext)
+		assert.Equal(t, text, string(out), ext)
+	}
+
+	_, err := Compress(io.Discard, ".bz2")
+	assert.NotNil(t, err)
+}
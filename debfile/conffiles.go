@@ -0,0 +1,67 @@
+package debfile
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+type Conffile struct {
+	Path string
+	// RemoveOnUpgrade is set when the entry carries the 'remove-on-upgrade' flag, which asks dpkg to remove the file
+	// when the package is upgraded rather than treating it as an ordinary conffile.
+	RemoveOnUpgrade bool
+}
+
+func (c Conffile) String() string {
+	if c.RemoveOnUpgrade {
+		return "remove-on-upgrade " + c.Path
+	}
+	return c.Path
+}
+
+// ParseConffiles parses the 'conffiles' control file, which contains one absolute path per line, each optionally
+// preceded by whitespace-separated flags.
+func ParseConffiles(r io.Reader) ([]Conffile, error) {
+	var conffiles []Conffile
+
+	sc := bufio.NewScanner(r)
+	for lineNo := 1; sc.Scan(); lineNo++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+
+		// The path is always the last thing on the line, and it must begin with a '/'; anything before it is a flag.
+		i := strings.Index(line, "/")
+		if i == -1 {
+			return nil, errors.Errorf("line %d: conffile path is not absolute: %q", lineNo, line)
+		}
+		c := Conffile{Path: line[i:]}
+		for _, flag := range strings.Fields(line[:i]) {
+			switch flag {
+			case "remove-on-upgrade":
+				c.RemoveOnUpgrade = true
+			default:
+				return nil, errors.Errorf("line %d: unknown conffile flag: %q", lineNo, flag)
+			}
+		}
+		conffiles = append(conffiles, c)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, errors.Wrap(err, "failed to read conffiles")
+	}
+
+	return conffiles, nil
+}
+
+func (d *debFile) Conffiles() ([]Conffile, error) {
+	e, ok := d.control.Get("conffiles")
+	if !ok {
+		return nil, nil
+	}
+	return ParseConffiles(bytes.NewReader(e.Data))
+}
@@ -3,8 +3,6 @@ package debfile
 import (
 	"archive/tar"
 	"bytes"
-	"compress/bzip2"
-	"compress/gzip"
 	"fmt"
 	"io"
 	"os"
@@ -12,14 +10,34 @@ import (
 	"strings"
 
 	ar "github.com/blakesmith/ar"
-	"github.com/lxq/lzma"
+	"github.com/kelleyk/godebian/debcontrol"
 	"github.com/pkg/errors"
-	xz "github.com/smira/go-xz"
 )
 
 type DebFile interface {
 	Control() Tarball
 	Data() Tarball
+
+	// Package returns the parsed contents of the 'control' control file.
+	Package() (*debcontrol.BinaryPackage, error)
+
+	// MaintainerScripts returns the maintainer scripts (preinst, postinst, prerm, postrm and config) present in the
+	// control tarball, in that order.
+	MaintainerScripts() []MaintainerScript
+	// Conffiles returns the parsed contents of the 'conffiles' control file.
+	Conffiles() ([]Conffile, error)
+	// Triggers returns the parsed contents of the 'triggers' control file.
+	Triggers() ([]Trigger, error)
+	// Shlibs returns the parsed contents of the 'shlibs' control file.
+	Shlibs() ([]Shlib, error)
+	// Symbols returns the parsed contents of the 'symbols' control file.
+	Symbols() ([]SymbolsLibrary, error)
+	// Templates returns the parsed contents of the debconf 'templates' control file.
+	Templates() ([]Template, error)
+
+	// Verify checks the contents of the data tarball against the checksum files (such as 'md5sums') in the control
+	// tarball.  If there are no checksum files, it returns ErrNoChecksums.
+	Verify() (*Verification, error)
 }
 
 type debFile struct {
@@ -117,13 +135,22 @@ func (d *debFile) loadFormat(h *ar.Header, buf []byte) error {
 }
 
 func (d *debFile) loadControl(h *ar.Header, buf []byte) error {
-	if h.Name != "control.tar.gz" {
+	// dpkg-deb(1) compresses the control tarball with gzip, xz or zstd, or not at all.
+	var ext string
+	switch h.Name {
+	case "control.tar":
+	case "control.tar.gz", "control.tar.xz", "control.tar.zst":
+		ext = filepath.Ext(h.Name)
+	default:
 		return errors.New("unexpected filename for control component")
 	}
-
-	r, err := gzip.NewReader(bytes.NewReader(buf))
+	r, err := Decompress(bytes.NewReader(buf), ext)
 	if err != nil {
-		return errors.Wrap(err, "failed to create gzip reader")
+		return errors.Wrapf(err, "unsupported compression method: %v", h.Name)
+	}
+	// N.B.: loadTarball stops at the end-of-archive marker, so an external decompressor must be stopped explicitly.
+	if c, ok := r.(io.Closer); ok {
+		defer c.Close()
 	}
 
 	d.control, err = loadTarball(tar.NewReader(r))
@@ -139,29 +166,17 @@ func (d *debFile) loadData(h *ar.Header, buf []byte) error {
 		return errors.New("unexpected filename for data component")
 	}
 
-	var err error
-	var r io.Reader = bytes.NewReader(buf)
-
 	// TODO: Make compression type visible somehow?
 	dataFileExt := filepath.Ext(h.Name)
-	switch dataFileExt {
-	case ".tar":
-	case ".xz":
-		r, err = xz.NewReader(r)
-		if err != nil {
-			return errors.Wrap(err, "failed to create xz reader")
-		}
-	case ".gz":
-		r, err = gzip.NewReader(r)
-		if err != nil {
-			return errors.Wrap(err, "failed to create gzip reader")
-		}
-	case ".bz2":
-		r = bzip2.NewReader(r)
-	case ".lz":
-		r = lzma.NewReader(r)
-	default:
-		return fmt.Errorf("unsupported compression method: %v (extension: %v)", h.Name, dataFileExt)
+	if dataFileExt == ".tar" {
+		dataFileExt = ""
+	}
+	r, err := Decompress(bytes.NewReader(buf), dataFileExt)
+	if err != nil {
+		return errors.Wrapf(err, "unsupported compression method: %v", h.Name)
+	}
+	if c, ok := r.(io.Closer); ok {
+		defer c.Close()
 	}
 
 	d.data, err = loadTarball(tar.NewReader(r))
@@ -171,3 +186,15 @@ func (d *debFile) loadData(h *ar.Header, buf []byte) error {
 
 	return nil
 }
+
+func (d *debFile) Package() (*debcontrol.BinaryPackage, error) {
+	e, ok := d.control.Get("control")
+	if !ok {
+		return nil, errors.New("package has no control file")
+	}
+	p, err := debcontrol.NewReader(bytes.NewReader(e.Data)).Next()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse control file")
+	}
+	return debcontrol.ParseBinaryPackage(p)
+}
@@ -0,0 +1,246 @@
+package debfile
+
+import (
+	"archive/tar"
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	ar "github.com/blakesmith/ar"
+	"github.com/stretchr/testify/assert"
+)
+
+// testEntry describes a member of one of the tarballs built by buildTestDeb.  Names ending in '/' are directories;
+// names containing " -> " are symlinks.
+type testEntry struct {
+	Name string
+	Data string
+}
+
+// buildTestTarball builds a tarball, compressed as implied by the file extension ext (see Compress).
+func buildTestTarball(t *testing.T, entries []testEntry, ext string) []byte {
+	var buf bytes.Buffer
+	cw, err := Compress(&buf, ext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tw := tar.NewWriter(cw)
+	for _, e := range entries {
+		h := &tar.Header{Name: "." + e.Name, Mode: 0644, Typeflag: tar.TypeReg, Size: int64(len(e.Data))}
+		switch {
+		case strings.HasSuffix(e.Name, "/"):
+			h.Typeflag, h.Mode, h.Size = tar.TypeDir, 0755, 0
+		case strings.Contains(e.Name, " -> "):
+			parts := strings.SplitN(e.Name, " -> ", 2)
+			h.Name, h.Linkname = "."+parts[0], parts[1]
+			h.Typeflag, h.Mode, h.Size = tar.TypeSymlink, 0777, 0
+		}
+		if err := tw.WriteHeader(h); err != nil {
+			t.Fatal(err)
+		}
+		if h.Typeflag == tar.TypeReg {
+			if _, err := tw.Write([]byte(e.Data)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func buildTestDeb(t *testing.T, control, data []testEntry) []byte {
+	return buildCompressedTestDeb(t, ".gz", control, data)
+}
+
+// buildCompressedTestDeb builds a package whose control and data tarballs are both compressed as implied by ext.
+func buildCompressedTestDeb(t *testing.T, ext string, control, data []testEntry) []byte {
+	var buf bytes.Buffer
+	w := ar.NewWriter(&buf)
+	if err := w.WriteGlobalHeader(); err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range []struct {
+		name string
+		data []byte
+	}{
+		{"debian-binary", []byte("2.0\n")},
+		{"control.tar" + ext, buildTestTarball(t, control, ext)},
+		{"data.tar" + ext, buildTestTarball(t, data, ext)},
+	} {
+		if err := w.WriteHeader(&ar.Header{Name: m.name, Mode: 0644, Size: int64(len(m.data))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write(m.data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return buf.Bytes()
+}
+
+func loadTestDeb(t *testing.T, control, data []testEntry) DebFile {
+	d, err := Load(bytes.NewReader(buildTestDeb(t, control, data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestLoad(t *testing.T) {
+	d := loadTestDeb(t,
+		[]testEntry{{"/", ""}, {"/control", "Package: foo\n"}},
+		[]testEntry{{"/", ""}, {"/usr/", ""}, {"/usr/bin/", ""}, {"/usr/bin/foo", "hello"}},
+	)
+
+	var names []string
+	for name := range d.Data().Contents {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	assert.Equal(t, []string{"/", "/usr/", "/usr/bin/", "/usr/bin/foo"}, names)
+
+	e, ok := d.Data().Get("usr/bin/foo")
+	if assert.True(t, ok) {
+		assert.True(t, e.IsReg())
+		assert.Equal(t, "hello", string(e.Data))
+	}
+}
+
+func TestLoadCompressed(t *testing.T) {
+	for ext, program := range map[string]string{"": "", ".gz": "", ".xz": "xz", ".zst": "zstd"} {
+		if program != "" {
+			if _, err := exec.LookPath(program); err != nil {
+				t.Logf("skipping %v: %v", ext, err)
+				continue
+			}
+		}
+		d, err := Load(bytes.NewReader(buildCompressedTestDeb(t, ext,
+			[]testEntry{{"/control", "Package: foo\n"}}, []testEntry{{"/usr/bin/foo", "hello"}})))
+		if !assert.NoError(t, err, ext) {
+			continue
+		}
+		e, ok := d.Control().Get("control")
+		if assert.True(t, ok, ext) {
+			assert.Equal(t, "Package: foo\n", string(e.Data), ext)
+		}
+		e, ok = d.Data().Get("usr/bin/foo")
+		if assert.True(t, ok, ext) {
+			assert.Equal(t, "hello", string(e.Data), ext)
+		}
+	}
+}
+
+// zombieChildren counts the child processes of the test that have exited but have not been waited for.
+func zombieChildren(t *testing.T) int {
+	stats, _ := filepath.Glob("/proc/[0-9]*/stat")
+	if len(stats) == 0 {
+		t.Skip("/proc is not available")
+	}
+	n := 0
+	for _, name := range stats {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			continue
+		}
+		// The process name is in parentheses and may contain spaces, so the fields are counted from the last ')'.
+		fields := strings.Fields(string(data[bytes.LastIndexByte(data, ')')+1:]))
+		if len(fields) > 1 && fields[0] == "Z" && fields[1] == strconv.Itoa(os.Getpid()) {
+			n++
+		}
+	}
+	return n
+}
+
+func TestLoadStopsDecompressor(t *testing.T) {
+	if _, err := exec.LookPath("zstd"); err != nil {
+		t.Skip(err)
+	}
+	deb := buildCompressedTestDeb(t, ".zst", []testEntry{{"/control", "Package: foo\n"}},
+		[]testEntry{{"/usr/bin/foo", "hello"}})
+
+	before := zombieChildren(t)
+	for i := 0; i < 5; i++ {
+		_, err := Load(bytes.NewReader(deb))
+		assert.NoError(t, err)
+	}
+	// The tarballs are not read to the end, so each zstd process must have been stopped and waited for.
+	assert.Equal(t, before, zombieChildren(t))
+}
+
+func TestMaintainerScripts(t *testing.T) {
+	d := loadTestDeb(t,
+		[]testEntry{
+			{"/postrm", "#!/usr/bin/perl -w\nexit 0;\n"},
+			{"/preinst", "#! /bin/sh -e\r\nexit 0\n"},
+			{"/config", "exit 0\n"},
+		},
+		[]testEntry{{"/", ""}},
+	)
+
+	scripts := d.MaintainerScripts()
+	if !assert.Len(t, scripts, 3) {
+		return
+	}
+
+	assert.Equal(t, "preinst", scripts[0].Name)
+	assert.Equal(t, "/bin/sh", scripts[0].Interpreter)
+	assert.Equal(t, []string{"-e"}, scripts[0].InterpreterArgs)
+
+	assert.Equal(t, "postrm", scripts[1].Name)
+	assert.Equal(t, "/usr/bin/perl", scripts[1].Interpreter)
+	assert.Equal(t, "#!/usr/bin/perl -w\nexit 0;\n", string(scripts[1].Data))
+
+	assert.Equal(t, "config", scripts[2].Name)
+	assert.Equal(t, "", scripts[2].Interpreter)
+}
+
+func TestParseConffiles(t *testing.T) {
+	conffiles, err := ParseConffiles(strings.NewReader("/etc/foo.conf\n\nremove-on-upgrade /etc/foo/old.conf\n"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []Conffile{
+		{Path: "/etc/foo.conf"},
+		{Path: "/etc/foo/old.conf", RemoveOnUpgrade: true},
+	}, conffiles)
+	assert.Equal(t, "remove-on-upgrade /etc/foo/old.conf", conffiles[1].String())
+
+	_, err = ParseConffiles(strings.NewReader("etc/foo.conf\n"))
+	assert.Error(t, err)
+	_, err = ParseConffiles(strings.NewReader("bogus-flag /etc/foo.conf\n"))
+	assert.Error(t, err)
+}
+
+func TestParseTriggers(t *testing.T) {
+	triggers, err := ParseTriggers(strings.NewReader(
+		"# comment\ninterest-noawait /usr/share/icons\nactivate ldconfig\n  activate-await  update-foo \n"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []Trigger{
+		{Directive: TriggerInterestNoAwait, Name: "/usr/share/icons"},
+		{Directive: TriggerActivate, Name: "ldconfig"},
+		{Directive: TriggerActivateAwait, Name: "update-foo"},
+	}, triggers)
+
+	assert.True(t, triggers[0].Directive.IsInterest())
+	assert.False(t, triggers[0].Directive.Await())
+	assert.True(t, triggers[0].IsFileTrigger())
+	assert.False(t, triggers[1].Directive.IsInterest())
+	assert.True(t, triggers[1].Directive.Await())
+	assert.Equal(t, "activate ldconfig", triggers[1].String())
+
+	_, err = ParseTriggers(strings.NewReader("interested foo\n"))
+	assert.Error(t, err)
+	_, err = ParseTriggers(strings.NewReader("interest\n"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,53 @@
+package debfile
+
+import (
+	"bytes"
+	"strings"
+)
+
+// MaintainerScriptNames lists the names of the maintainer scripts that may appear in a package's control tarball, in
+// the order in which MaintainerScripts returns them.
+var MaintainerScriptNames = []string{"preinst", "postinst", "prerm", "postrm", "config"}
+
+type MaintainerScript struct {
+	// Name is the name of the script inside of the control tarball (e.g. "postinst").
+	Name string
+	// Interpreter is the path to the interpreter named by the script's "#!" line (e.g. "/bin/sh"), or the empty string
+	// if the script does not begin with one.
+	Interpreter string
+	// InterpreterArgs are any whitespace-separated arguments that follow the interpreter on the "#!" line.
+	InterpreterArgs []string
+	Data            []byte
+}
+
+// ParseMaintainerScript builds a MaintainerScript from the contents of a control tarball member.
+func ParseMaintainerScript(name string, data []byte) MaintainerScript {
+	s := MaintainerScript{Name: name, Data: data}
+
+	if !bytes.HasPrefix(data, []byte("#!")) {
+		return s
+	}
+	line := data[2:]
+	if i := bytes.IndexByte(line, '\n'); i != -1 {
+		line = line[:i]
+	}
+	fields := strings.Fields(strings.TrimSuffix(string(line), "\r"))
+	if len(fields) > 0 {
+		s.Interpreter = fields[0]
+		s.InterpreterArgs = fields[1:]
+	}
+
+	return s
+}
+
+func (d *debFile) MaintainerScripts() []MaintainerScript {
+	var scripts []MaintainerScript
+	for _, name := range MaintainerScriptNames {
+		e, ok := d.control.Get(name)
+		if !ok || !e.IsReg() {
+			continue
+		}
+		scripts = append(scripts, ParseMaintainerScript(name, e.Data))
+	}
+	return scripts
+}
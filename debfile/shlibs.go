@@ -0,0 +1,79 @@
+package debfile
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+
+	"github.com/kelleyk/godebian/debrelation"
+	"github.com/pkg/errors"
+)
+
+// Ref.: deb-shlibs(5).
+
+type Shlib struct {
+	// Type is the package type that the entry applies to (e.g. "udeb"), or the empty string for entries that apply to
+	// ordinary binary packages.
+	Type         string
+	Library      string
+	Version      string
+	Dependencies debrelation.Relations
+}
+
+// ParseShlibs parses a 'shlibs' file.  Blank lines and lines starting with '#' are ignored.
+func ParseShlibs(r io.Reader) ([]Shlib, error) {
+	var shlibs []Shlib
+
+	sc := bufio.NewScanner(r)
+	for lineNo := 1; sc.Scan(); lineNo++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var s Shlib
+		field, rest := cutField(line)
+		if strings.HasSuffix(field, ":") {
+			s.Type = strings.TrimSuffix(field, ":")
+			field, rest = cutField(rest)
+		}
+		s.Library = field
+		s.Version, rest = cutField(rest)
+		if s.Library == "" || s.Version == "" {
+			return nil, errors.Errorf("line %d: expected a library name and a version: %q", lineNo, line)
+		}
+
+		deps, err := debrelation.Parse(rest)
+		if err != nil {
+			return nil, errors.Wrapf(err, "line %d", lineNo)
+		}
+		s.Dependencies = deps
+
+		shlibs = append(shlibs, s)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, errors.Wrap(err, "failed to read shlibs")
+	}
+
+	return shlibs, nil
+}
+
+func (d *debFile) Shlibs() ([]Shlib, error) {
+	e, ok := d.control.Get("shlibs")
+	if !ok {
+		return nil, nil
+	}
+	return ParseShlibs(bytes.NewReader(e.Data))
+}
+
+// cutField splits off the first whitespace-delimited field of s, returning it and the remainder with leading
+// whitespace removed.
+func cutField(s string) (string, string) {
+	s = strings.TrimLeft(s, " \t")
+	i := strings.IndexAny(s, " \t")
+	if i == -1 {
+		return s, ""
+	}
+	return s[:i], strings.TrimLeft(s[i:], " \t")
+}
@@ -0,0 +1,282 @@
+package debfile
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/kelleyk/godebian/debrelation"
+	"github.com/kelleyk/godebian/debversion"
+	"github.com/pkg/errors"
+)
+
+// Ref.: deb-symbols(5) and dpkg-gensymbols(1).
+
+const minVerPlaceholder = "#MINVER#"
+
+// maxSymbolsIncludeDepth bounds the nesting of '#include' directives so that an include cycle produces an error
+// rather than unbounded recursion.
+const maxSymbolsIncludeDepth = 16
+
+// DependencyTemplate is a dependency template from a symbols file, such as "libfoo1 #MINVER#".  The '#MINVER#'
+// placeholder is replaced by a versioned constraint when the template is expanded.
+type DependencyTemplate string
+
+func (t DependencyTemplate) HasMinVer() bool {
+	return strings.Contains(string(t), minVerPlaceholder)
+}
+
+// Expand substitutes minVer for the '#MINVER#' placeholder and parses the result.  If minVer is nil or "0" (which
+// dpkg-gensymbols uses for symbols that have always been present), the placeholder is simply removed.
+func (t DependencyTemplate) Expand(minVer *debversion.DebianVersion) (debrelation.Relations, error) {
+	repl := ""
+	if minVer != nil && minVer.String() != "0" {
+		repl = "(>= " + minVer.String() + ")"
+	}
+	rs, err := debrelation.Parse(strings.Replace(string(t), minVerPlaceholder, repl, -1))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to expand dependency template %q", string(t))
+	}
+	return rs, nil
+}
+
+type SymbolTag struct {
+	Name  string
+	Value string
+}
+
+func (t SymbolTag) String() string {
+	if t.Value == "" {
+		return t.Name
+	}
+	return t.Name + "=" + t.Value
+}
+
+type Symbol struct {
+	// Name is the symbol name including its version (e.g. "foo@Base").  For pattern symbols, this is the pattern.
+	Name       string
+	Tags       []SymbolTag
+	MinVersion debversion.DebianVersion
+	// DependencyID selects the dependency template used for this symbol; see SymbolsLibrary.Template.
+	DependencyID int
+}
+
+// Tag returns the value of the tag with the given name and whether or not the symbol has that tag.
+func (s Symbol) Tag(name string) (string, bool) {
+	for _, t := range s.Tags {
+		if t.Name == name {
+			return t.Value, true
+		}
+	}
+	return "", false
+}
+
+// IsWildcard is true for the legacy "*@VERSION" form, which matches every symbol with the given version.
+func (s Symbol) IsWildcard() bool {
+	return strings.HasPrefix(s.Name, "*@")
+}
+
+// IsPattern is true when the symbol matches other symbols rather than naming a single one: wildcards and symbols
+// tagged with 'c++', 'regex' or 'symver'.
+func (s Symbol) IsPattern() bool {
+	if s.IsWildcard() {
+		return true
+	}
+	for _, name := range []string{"c++", "regex", "symver"} {
+		if _, ok := s.Tag(name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+type SymbolsLibrary struct {
+	Soname     string
+	Dependency DependencyTemplate
+	// Alternatives are the additional templates introduced by '|' lines; they are selected by a nonzero
+	// Symbol.DependencyID.
+	Alternatives []DependencyTemplate
+	// Fields holds meta-information given by "* Field: value" lines (e.g. "Build-Depends-Package").
+	Fields  map[string]string
+	Symbols []Symbol
+}
+
+// Template returns the dependency template with the given ID: 0 is the main template and n is the n-th alternative.
+func (l SymbolsLibrary) Template(id int) (DependencyTemplate, error) {
+	switch {
+	case id == 0:
+		return l.Dependency, nil
+	case id > 0 && id <= len(l.Alternatives):
+		return l.Alternatives[id-1], nil
+	default:
+		return "", errors.Errorf("no dependency template with ID %d for %v", id, l.Soname)
+	}
+}
+
+// SymbolsIncludeFunc opens the file named by an '#include' directive.
+type SymbolsIncludeFunc func(name string) (io.ReadCloser, error)
+
+// ParseSymbols parses a 'symbols' file.  The contents of files named by '#include' directives are spliced in at the
+// point where the directive appears; include may be nil, in which case encountering a directive is an error.
+func ParseSymbols(r io.Reader, include SymbolsIncludeFunc) ([]SymbolsLibrary, error) {
+	p := &symbolsParser{include: include}
+	if err := p.parse(r, 0); err != nil {
+		return nil, err
+	}
+	return p.libs, nil
+}
+
+func (d *debFile) Symbols() ([]SymbolsLibrary, error) {
+	e, ok := d.control.Get("symbols")
+	if !ok {
+		return nil, nil
+	}
+	return ParseSymbols(bytes.NewReader(e.Data), nil)
+}
+
+type symbolsParser struct {
+	include SymbolsIncludeFunc
+	libs    []SymbolsLibrary
+}
+
+func (p *symbolsParser) current() *SymbolsLibrary {
+	if len(p.libs) == 0 {
+		return nil
+	}
+	return &p.libs[len(p.libs)-1]
+}
+
+func (p *symbolsParser) parse(r io.Reader, depth int) error {
+	sc := bufio.NewScanner(r)
+	for lineNo := 1; sc.Scan(); lineNo++ {
+		if err := p.parseLine(sc.Text(), depth); err != nil {
+			return errors.Wrapf(err, "line %d", lineNo)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return errors.Wrap(err, "failed to read symbols")
+	}
+	return nil
+}
+
+func (p *symbolsParser) parseLine(line string, depth int) error {
+	switch {
+	case strings.TrimSpace(line) == "":
+		return nil
+
+	case strings.HasPrefix(line, "#include"):
+		return p.parseInclude(strings.TrimSpace(strings.TrimPrefix(line, "#include")), depth)
+
+	case strings.HasPrefix(line, "#"):
+		// Comments, including the '#MISSING: ...#' and '#DEPRECATED: ...#' annotations left by dpkg-gensymbols.
+		return nil
+
+	case strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t"):
+		lib := p.current()
+		if lib == nil {
+			return errors.New("symbol appears before any library")
+		}
+		sym, err := parseSymbolLine(strings.TrimSpace(line))
+		if err != nil {
+			return err
+		}
+		lib.Symbols = append(lib.Symbols, sym)
+
+	case strings.HasPrefix(line, "|"):
+		lib := p.current()
+		if lib == nil {
+			return errors.New("alternative dependency template appears before any library")
+		}
+		lib.Alternatives = append(lib.Alternatives, DependencyTemplate(strings.TrimSpace(line[1:])))
+
+	case strings.HasPrefix(line, "*"):
+		lib := p.current()
+		if lib == nil {
+			return errors.New("meta-information field appears before any library")
+		}
+		name, value, ok := strings.Cut(strings.TrimSpace(line[1:]), ":")
+		if !ok {
+			return errors.Errorf("malformed meta-information field: %q", line)
+		}
+		lib.Fields[strings.TrimSpace(name)] = strings.TrimSpace(value)
+
+	default:
+		soname, dep := cutField(strings.TrimSpace(line))
+		p.libs = append(p.libs, SymbolsLibrary{
+			Soname:     soname,
+			Dependency: DependencyTemplate(dep),
+			Fields:     make(map[string]string),
+		})
+	}
+	return nil
+}
+
+func (p *symbolsParser) parseInclude(arg string, depth int) error {
+	name, err := strconv.Unquote(arg)
+	if err != nil {
+		return errors.Errorf("malformed #include directive: %q", arg)
+	}
+	if p.include == nil {
+		return errors.Errorf("cannot resolve #include %q", name)
+	}
+	if depth >= maxSymbolsIncludeDepth {
+		return errors.Errorf("#include directives nested too deeply at %q", name)
+	}
+
+	f, err := p.include(name)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open included file %q", name)
+	}
+	defer f.Close()
+	return errors.Wrapf(p.parse(f, depth+1), "in included file %q", name)
+}
+
+// parseSymbolLine parses a line of the form '[(tag|tag=value|...)]name min-version [dependency-id]', where name
+// may be double-quoted if it contains spaces.
+func parseSymbolLine(line string) (Symbol, error) {
+	var sym Symbol
+
+	if strings.HasPrefix(line, "(") {
+		end := strings.Index(line, ")")
+		if end == -1 {
+			return sym, errors.Errorf("unterminated tag list: %q", line)
+		}
+		for _, tag := range strings.Split(line[1:end], "|") {
+			name, value, _ := strings.Cut(tag, "=")
+			sym.Tags = append(sym.Tags, SymbolTag{Name: name, Value: value})
+		}
+		line = line[end+1:]
+	}
+
+	var rest string
+	if strings.HasPrefix(line, "\"") {
+		end := strings.Index(line[1:], "\"")
+		if end == -1 {
+			return sym, errors.Errorf("unterminated quoted symbol name: %q", line)
+		}
+		sym.Name, rest = line[1:end+1], line[end+2:]
+	} else {
+		sym.Name, rest = cutField(line)
+	}
+
+	fields := strings.Fields(rest)
+	if sym.Name == "" || len(fields) < 1 || len(fields) > 2 {
+		return sym, errors.Errorf("malformed symbol line: %q", line)
+	}
+	v, err := debversion.FromString(fields[0])
+	if err != nil {
+		return sym, errors.Wrapf(err, "failed to parse minimum version for %v", sym.Name)
+	}
+	sym.MinVersion = v
+	if len(fields) == 2 {
+		id, err := strconv.Atoi(fields[1])
+		if err != nil || id < 0 {
+			return sym, errors.Errorf("malformed dependency template ID for %v: %q", sym.Name, fields[1])
+		}
+		sym.DependencyID = id
+	}
+
+	return sym, nil
+}
@@ -0,0 +1,121 @@
+package debfile
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/kelleyk/godebian/debversion"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseShlibs(t *testing.T) {
+	shlibs, err := ParseShlibs(strings.NewReader(
+		"# comment\nlibfoo 1 libfoo1 (>= 1.2-3)\nudeb: libfoo 1 libfoo1-udeb\nlibbar-2.0 0 libbar0 | libbar-compat\n"))
+	if !assert.NoError(t, err) || !assert.Len(t, shlibs, 3) {
+		return
+	}
+
+	assert.Equal(t, "", shlibs[0].Type)
+	assert.Equal(t, "libfoo", shlibs[0].Library)
+	assert.Equal(t, "1", shlibs[0].Version)
+	assert.Equal(t, "libfoo1 (>= 1.2-3)", shlibs[0].Dependencies.String())
+	assert.Equal(t, ">=", shlibs[0].Dependencies[0][0].Constraint.Op.String())
+
+	assert.Equal(t, "udeb", shlibs[1].Type)
+	assert.Equal(t, "libfoo1-udeb", shlibs[1].Dependencies.String())
+
+	assert.Equal(t, "libbar-2.0", shlibs[2].Library)
+	assert.Len(t, shlibs[2].Dependencies[0], 2)
+
+	for _, s := range []string{
+		"libfoo\n",
+		"libfoo 1 libfoo1 (>= 1.0\n",
+		"libfoo 1 libfoo1 (~ 1.0)\n",
+		"libfoo 1 libfoo1 bar\n",
+		"libfoo 1 libfoo1,,bar\n",
+	} {
+		_, err = ParseShlibs(strings.NewReader(s))
+		assert.Error(t, err, s)
+	}
+}
+
+const testSymbols = `libfoo.so.1 libfoo1 #MINVER#
+| libfoo1-extra (>= 1.0)
+* Build-Depends-Package: libfoo-dev
+ foo_init@Base 1.0
+ foo_new@Base 1.2-1 1
+ (c++)"foo::Bar::baz(int) const@Base" 1.1
+ (regex|optional)"^foo_private_.*@Base$" 0
+ (arch=amd64 i386)foo_x86@Base 1.3
+ *@FOO_PRIVATE 1.0
+#MISSING: 1.4# foo_gone@Base 1.0
+#include "libfoo.symbols.common"
+libfoo-extra.so.0 libfoo1-extra #MINVER#
+ extra@Base 1.0
+`
+
+func TestParseSymbols(t *testing.T) {
+	include := func(name string) (io.ReadCloser, error) {
+		assert.Equal(t, "libfoo.symbols.common", name)
+		return io.NopCloser(strings.NewReader(" foo_common@Base 0.9\n")), nil
+	}
+	libs, err := ParseSymbols(strings.NewReader(testSymbols), include)
+	if !assert.NoError(t, err) || !assert.Len(t, libs, 2) {
+		return
+	}
+
+	lib := libs[0]
+	assert.Equal(t, "libfoo.so.1", lib.Soname)
+	assert.Equal(t, DependencyTemplate("libfoo1 #MINVER#"), lib.Dependency)
+	assert.Equal(t, []DependencyTemplate{"libfoo1-extra (>= 1.0)"}, lib.Alternatives)
+	assert.Equal(t, map[string]string{"Build-Depends-Package": "libfoo-dev"}, lib.Fields)
+	if !assert.Len(t, lib.Symbols, 7) {
+		return
+	}
+
+	assert.Equal(t, Symbol{Name: "foo_init@Base", MinVersion: debversion.DebianVersion{UpstreamVersion: "1.0"}},
+		lib.Symbols[0])
+	assert.Equal(t, 1, lib.Symbols[1].DependencyID)
+	assert.Equal(t, "1.2-1", lib.Symbols[1].MinVersion.String())
+
+	assert.Equal(t, "foo::Bar::baz(int) const@Base", lib.Symbols[2].Name)
+	assert.True(t, lib.Symbols[2].IsPattern())
+
+	_, optional := lib.Symbols[3].Tag("optional")
+	assert.True(t, optional)
+	assert.Equal(t, "^foo_private_.*@Base$", lib.Symbols[3].Name)
+
+	arch, ok := lib.Symbols[4].Tag("arch")
+	assert.True(t, ok)
+	assert.Equal(t, "amd64 i386", arch)
+	assert.False(t, lib.Symbols[4].IsPattern())
+
+	assert.True(t, lib.Symbols[5].IsWildcard())
+	assert.Equal(t, "foo_common@Base", lib.Symbols[6].Name)
+
+	tmpl, err := lib.Template(lib.Symbols[0].DependencyID)
+	if assert.NoError(t, err) {
+		rs, err := tmpl.Expand(&lib.Symbols[0].MinVersion)
+		if assert.NoError(t, err) {
+			assert.Equal(t, "libfoo1 (>= 1.0)", rs.String())
+		}
+		rs, err = tmpl.Expand(nil)
+		if assert.NoError(t, err) {
+			assert.Equal(t, "libfoo1", rs.String())
+		}
+	}
+	_, err = lib.Template(2)
+	assert.Error(t, err)
+
+	_, err = ParseSymbols(strings.NewReader(testSymbols), nil)
+	assert.Error(t, err)
+}
+
+func TestParseSymbolsIncludeCycle(t *testing.T) {
+	include := func(name string) (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader("#include \"self\"\n")), nil
+	}
+	_, err := ParseSymbols(strings.NewReader("#include \"self\"\n"), include)
+	assert.Error(t, err)
+}
@@ -4,12 +4,23 @@ import (
 	"archive/tar"
 	"fmt"
 	"io"
+	"strings"
 )
 
 type Tarball struct {
 	Contents map[string]TarballEntry
 }
 
+// Get returns the entry with the given name.  Entries are keyed by their path inside of the tarball with the leading
+// '.' removed (e.g. "/usr/bin/foo"); for convenience, a name without a leading '/' is treated as though it had one.
+func (t Tarball) Get(name string) (TarballEntry, bool) {
+	if !strings.HasPrefix(name, "/") {
+		name = "/" + name
+	}
+	e, ok := t.Contents[name]
+	return e, ok
+}
+
 type TarballEntry struct {
 	Header *tar.Header
 	Data   []byte
@@ -62,19 +73,13 @@ func loadTarball(r *tar.Reader) (Tarball, error) {
 	return tarball, nil
 }
 
-// TODO: Clean this up.
 func tarReadAll(r *tar.Reader, buf []byte) error {
-	for {
-		if len(buf) == 0 {
-			return nil
-		}
-		n, err := r.Read(buf)
-		if n == 0 && err == io.EOF {
+	// N.B.: The tar reader may return io.EOF alongside the final bytes of an entry, which io.ReadFull tolerates.
+	if _, err := io.ReadFull(r, buf); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
 			return fmt.Errorf("got unexpectedly early EOF")
 		}
-		if err != nil {
-			return err
-		}
-		buf = buf[n:]
+		return err
 	}
+	return nil
 }
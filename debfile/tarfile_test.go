@@ -0,0 +1,33 @@
+package debfile
+
+import (
+	"archive/tar"
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadTarball(t *testing.T) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, e := range []struct{ name, data string }{{"./usr/bin/foo", "hello"}, {"./usr/bin/empty", ""}} {
+		h := &tar.Header{Name: e.name, Mode: 0644, Typeflag: tar.TypeReg, Size: int64(len(e.data))}
+		if err := tw.WriteHeader(h); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(e.data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The tar reader returns io.EOF along with the last bytes of an entry; that must not be mistaken for an error.
+	tarball, err := loadTarball(tar.NewReader(&buf))
+	if assert.NoError(t, err) {
+		assert.Equal(t, "hello", string(tarball.Contents["/usr/bin/foo"].Data))
+		assert.Equal(t, "", string(tarball.Contents["/usr/bin/empty"].Data))
+	}
+}
@@ -0,0 +1,157 @@
+package debfile
+
+import (
+	"bytes"
+	"io"
+	"strings"
+
+	"github.com/kelleyk/godebian/debcontrol"
+	"github.com/pkg/errors"
+)
+
+// Ref.: debconf-devel(7), "THE TEMPLATES FILE".
+
+type TemplateType string
+
+const (
+	TemplateString      TemplateType = "string"
+	TemplatePassword    TemplateType = "password"
+	TemplateBoolean     TemplateType = "boolean"
+	TemplateSelect      TemplateType = "select"
+	TemplateMultiselect TemplateType = "multiselect"
+	TemplateNote        TemplateType = "note"
+	TemplateText        TemplateType = "text"
+	TemplateError       TemplateType = "error"
+	TemplateTitle       TemplateType = "title"
+)
+
+var templateTypes = []TemplateType{
+	TemplateString, TemplatePassword, TemplateBoolean, TemplateSelect, TemplateMultiselect, TemplateNote, TemplateText,
+	TemplateError, TemplateTitle,
+}
+
+// TemplateDescription is a template's description in a single language.
+type TemplateDescription struct {
+	Short    string
+	Extended string
+}
+
+type Template struct {
+	// Name is the value of the Template field (e.g. "foo/enable-bar").
+	Name    string
+	Type    TemplateType
+	Default string
+	Choices []string
+	// Description is the untranslated description.
+	Description TemplateDescription
+
+	// LocalizedChoices and LocalizedDescriptions are keyed by the suffix of the corresponding field name (e.g.
+	// "de.UTF-8" for "Description-de.UTF-8").
+	LocalizedChoices      map[string][]string
+	LocalizedDescriptions map[string]TemplateDescription
+
+	// Fields is the paragraph that the template was parsed from.
+	Fields debcontrol.Paragraph
+}
+
+// IsQuestion is false for the template types that only display information and never yield an answer.
+func (t Template) IsQuestion() bool {
+	switch t.Type {
+	case TemplateNote, TemplateText, TemplateError, TemplateTitle:
+		return false
+	default:
+		return true
+	}
+}
+
+// ParseTemplates parses a debconf 'templates' file.
+func ParseTemplates(r io.Reader) ([]Template, error) {
+	ps, err := debcontrol.ParseParagraphs(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse templates")
+	}
+
+	templates := make([]Template, 0, len(ps))
+	for _, p := range ps {
+		t, err := templateFromParagraph(p)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, t)
+	}
+	return templates, nil
+}
+
+func templateFromParagraph(p debcontrol.Paragraph) (Template, error) {
+	t := Template{
+		Name:                  p.Value("Template"),
+		Type:                  TemplateType(p.Value("Type")),
+		Default:               p.Value("Default"),
+		LocalizedChoices:      make(map[string][]string),
+		LocalizedDescriptions: make(map[string]TemplateDescription),
+		Fields:                p,
+	}
+	if t.Name == "" {
+		return t, errors.New("template is missing the Template field")
+	}
+	if !isKnownTemplateType(t.Type) {
+		return t, errors.Errorf("template %v has unknown type %q", t.Name, t.Type)
+	}
+
+	for _, f := range p.Fields {
+		name, locale, _ := strings.Cut(f.Name, "-")
+		switch {
+		case strings.EqualFold(name, "Choices") && locale == "":
+			t.Choices = splitTemplateChoices(f.Value)
+		case strings.EqualFold(name, "Choices"):
+			t.LocalizedChoices[locale] = splitTemplateChoices(f.Value)
+		case strings.EqualFold(name, "Description") && locale == "":
+			t.Description.Short, t.Description.Extended = debcontrol.SplitDescription(f.Value)
+		case strings.EqualFold(name, "Description"):
+			var d TemplateDescription
+			d.Short, d.Extended = debcontrol.SplitDescription(f.Value)
+			t.LocalizedDescriptions[locale] = d
+		}
+	}
+
+	return t, nil
+}
+
+func isKnownTemplateType(typ TemplateType) bool {
+	for _, known := range templateTypes {
+		if typ == known {
+			return true
+		}
+	}
+	return false
+}
+
+// splitTemplateChoices splits a Choices field on commas; a comma that is part of a choice is escaped with a backslash.
+func splitTemplateChoices(s string) []string {
+	var choices []string
+	var cur strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && i+1 < len(s) && s[i+1] == ',':
+			cur.WriteByte(',')
+			i++
+		case s[i] == ',':
+			choices = append(choices, strings.TrimSpace(cur.String()))
+			cur.Reset()
+		default:
+			cur.WriteByte(s[i])
+		}
+	}
+	if c := strings.TrimSpace(cur.String()); c != "" || len(choices) > 0 {
+		choices = append(choices, c)
+	}
+	return choices
+}
+
+func (d *debFile) Templates() ([]Template, error) {
+	e, ok := d.control.Get("templates")
+	if !ok {
+		return nil, nil
+	}
+	return ParseTemplates(bytes.NewReader(e.Data))
+}
@@ -0,0 +1,61 @@
+package debfile
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testTemplates = `Template: foo/frontend
+Type: select
+Choices: dialog, readline, a\, b
+Choices-de.UTF-8: Dialog, Readline, a\, b
+Default: dialog
+Description: Interface to use:
+ Packages that use debconf for configuration share a common look and feel.
+ .
+ Pick one.
+Description-de.UTF-8: Zu verwendende Schnittstelle:
+ Pakete, die debconf verwenden, haben ein gemeinsames Erscheinungsbild.
+
+Template: foo/welcome
+Type: note
+Description: Welcome!
+`
+
+func TestParseTemplates(t *testing.T) {
+	templates, err := ParseTemplates(strings.NewReader(testTemplates))
+	if !assert.NoError(t, err) || !assert.Len(t, templates, 2) {
+		return
+	}
+
+	tmpl := templates[0]
+	assert.Equal(t, "foo/frontend", tmpl.Name)
+	assert.Equal(t, TemplateSelect, tmpl.Type)
+	assert.True(t, tmpl.IsQuestion())
+	assert.Equal(t, "dialog", tmpl.Default)
+	assert.Equal(t, []string{"dialog", "readline", "a, b"}, tmpl.Choices)
+	assert.Equal(t, map[string][]string{"de.UTF-8": {"Dialog", "Readline", "a, b"}}, tmpl.LocalizedChoices)
+	assert.Equal(t, TemplateDescription{
+		Short:    "Interface to use:",
+		Extended: "Packages that use debconf for configuration share a common look and feel.\n\nPick one.",
+	}, tmpl.Description)
+	assert.Equal(t, "Zu verwendende Schnittstelle:", tmpl.LocalizedDescriptions["de.UTF-8"].Short)
+
+	assert.Equal(t, TemplateNote, templates[1].Type)
+	assert.False(t, templates[1].IsQuestion())
+	assert.Nil(t, templates[1].Choices)
+
+	_, err = ParseTemplates(strings.NewReader("Template: foo/bar\nType: bogus\n"))
+	assert.Error(t, err)
+	_, err = ParseTemplates(strings.NewReader("Type: note\n"))
+	assert.Error(t, err)
+}
+
+func TestDebFileTemplates(t *testing.T) {
+	d := loadTestDeb(t, []testEntry{{"/templates", testTemplates}}, []testEntry{{"/", ""}})
+	templates, err := d.Templates()
+	assert.NoError(t, err)
+	assert.Len(t, templates, 2)
+}
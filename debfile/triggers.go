@@ -0,0 +1,117 @@
+package debfile
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Ref.: deb-triggers(5).
+
+type TriggerDirective int
+
+const (
+	TriggerUnknown TriggerDirective = iota
+	TriggerInterest
+	TriggerInterestAwait
+	TriggerInterestNoAwait
+	TriggerActivate
+	TriggerActivateAwait
+	TriggerActivateNoAwait
+)
+
+var triggerDirectiveNames = map[TriggerDirective]string{
+	TriggerInterest:        "interest",
+	TriggerInterestAwait:   "interest-await",
+	TriggerInterestNoAwait: "interest-noawait",
+	TriggerActivate:        "activate",
+	TriggerActivateAwait:   "activate-await",
+	TriggerActivateNoAwait: "activate-noawait",
+}
+
+func (d TriggerDirective) String() string {
+	if s, ok := triggerDirectiveNames[d]; ok {
+		return s
+	}
+	return "INVALID"
+}
+
+// IsInterest is true for the 'interest' family of directives and false for the 'activate' family.
+func (d TriggerDirective) IsInterest() bool {
+	switch d {
+	case TriggerInterest, TriggerInterestAwait, TriggerInterestNoAwait:
+		return true
+	default:
+		return false
+	}
+}
+
+// Await is false only for the '-noawait' variants; the plain directives are equivalent to the '-await' ones.
+func (d TriggerDirective) Await() bool {
+	switch d {
+	case TriggerInterestNoAwait, TriggerActivateNoAwait:
+		return false
+	default:
+		return true
+	}
+}
+
+type Trigger struct {
+	Directive TriggerDirective
+	// Name is the trigger name; for file triggers, this is an absolute path.
+	Name string
+}
+
+func (t Trigger) String() string {
+	return fmt.Sprintf("%s %s", t.Directive, t.Name)
+}
+
+// IsFileTrigger is true when the trigger name is an absolute path rather than an explicit trigger name.
+func (t Trigger) IsFileTrigger() bool {
+	return strings.HasPrefix(t.Name, "/")
+}
+
+// ParseTriggers parses the 'triggers' control file.  Blank lines and lines starting with '#' are ignored.
+func ParseTriggers(r io.Reader) ([]Trigger, error) {
+	var triggers []Trigger
+
+	sc := bufio.NewScanner(r)
+	for lineNo := 1; sc.Scan(); lineNo++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, errors.Errorf("line %d: expected a directive and a trigger name: %q", lineNo, line)
+		}
+		t := Trigger{Name: fields[1]}
+		for d, s := range triggerDirectiveNames {
+			if s == fields[0] {
+				t.Directive = d
+			}
+		}
+		if t.Directive == TriggerUnknown {
+			return nil, errors.Errorf("line %d: unknown trigger directive: %q", lineNo, fields[0])
+		}
+		triggers = append(triggers, t)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, errors.Wrap(err, "failed to read triggers")
+	}
+
+	return triggers, nil
+}
+
+func (d *debFile) Triggers() ([]Trigger, error) {
+	e, ok := d.control.Get("triggers")
+	if !ok {
+		return nil, nil
+	}
+	return ParseTriggers(bytes.NewReader(e.Data))
+}
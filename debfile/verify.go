@@ -0,0 +1,195 @@
+package debfile
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ErrNoChecksums is returned by Verify when the control tarball contains no checksum files.
+var ErrNoChecksums = errors.New("package contains no checksum files")
+
+// checksumFiles lists the checksum control files that Verify understands, in the order in which they are checked.
+// 'md5sums' is generated by dh_md5sums; 'sha256sums' is an optional, stronger equivalent in the same format.
+var checksumFiles = []struct {
+	name    string
+	newHash func() hash.Hash
+}{
+	{"md5sums", md5.New},
+	{"sha256sums", sha256.New},
+}
+
+type ChecksumEntry struct {
+	// Path is relative to the root of the filesystem, without a leading '/' (e.g. "usr/bin/foo").
+	Path   string
+	Digest string
+}
+
+// ParseChecksums parses a checksum control file such as 'md5sums', whose lines are of the form "<hex digest>  <path>"
+// (the format produced by md5sum(1) and friends).
+func ParseChecksums(r io.Reader) ([]ChecksumEntry, error) {
+	var entries []ChecksumEntry
+
+	sc := bufio.NewScanner(r)
+	for lineNo := 1; sc.Scan(); lineNo++ {
+		line := strings.TrimRight(sc.Text(), "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		i := strings.IndexAny(line, " \t")
+		if i <= 0 || i+1 >= len(line) {
+			return nil, errors.Errorf("line %d: expected a digest and a path: %q", lineNo, line)
+		}
+		digest, path := line[:i], line[i+1:]
+		if _, err := hex.DecodeString(digest); err != nil {
+			return nil, errors.Errorf("line %d: malformed digest: %q", lineNo, digest)
+		}
+		// The second separator character is ' ' for text mode or '*' for binary mode; the two are equivalent here.
+		if path[0] == ' ' || path[0] == '*' {
+			path = path[1:]
+		}
+		path = strings.TrimPrefix(strings.TrimPrefix(path, "./"), "/")
+		if path == "" {
+			return nil, errors.Errorf("line %d: missing path", lineNo)
+		}
+
+		entries = append(entries, ChecksumEntry{Path: path, Digest: strings.ToLower(digest)})
+	}
+	if err := sc.Err(); err != nil {
+		return nil, errors.Wrap(err, "failed to read checksums")
+	}
+
+	return entries, nil
+}
+
+type ChecksumMismatch struct {
+	Path string
+	// File is the name of the checksum control file that listed the path (e.g. "md5sums").
+	File     string
+	Expected string
+	Actual   string
+}
+
+type Verification struct {
+	// Checked lists the checksum control files that were found and checked.
+	Checked []string
+	// Missing lists paths that appear in a checksum file but not in the data tarball.
+	Missing []string
+	// Extra lists regular files in the data tarball that are not listed in any checksum file.  Conffiles are not
+	// included, since dh_md5sums deliberately omits them.
+	Extra      []string
+	Mismatches []ChecksumMismatch
+}
+
+func (v *Verification) OK() bool {
+	return len(v.Missing) == 0 && len(v.Extra) == 0 && len(v.Mismatches) == 0
+}
+
+// Err summarizes any problems found as an error, or returns nil if there were none.
+func (v *Verification) Err() error {
+	if v.OK() {
+		return nil
+	}
+	var problems []string
+	for _, p := range v.Missing {
+		problems = append(problems, fmt.Sprintf("missing file %q", p))
+	}
+	for _, p := range v.Extra {
+		problems = append(problems, fmt.Sprintf("unlisted file %q", p))
+	}
+	for _, m := range v.Mismatches {
+		problems = append(problems, fmt.Sprintf("%s mismatch for %q (expected %s, got %s)", m.File, m.Path, m.Expected,
+			m.Actual))
+	}
+	return errors.Errorf("package verification failed: %s", strings.Join(problems, "; "))
+}
+
+// Verify checks the contents of the data tarball against each of the checksum files in the control tarball.
+func (d *debFile) Verify() (*Verification, error) {
+	v := &Verification{}
+	listed := make(map[string]bool)
+	missing := make(map[string]bool)
+
+	for _, cf := range checksumFiles {
+		e, ok := d.control.Get(cf.name)
+		if !ok {
+			continue
+		}
+		entries, err := ParseChecksums(bytes.NewReader(e.Data))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse %v", cf.name)
+		}
+		v.Checked = append(v.Checked, cf.name)
+
+		for _, ce := range entries {
+			h := cf.newHash()
+			if len(ce.Digest) != 2*h.Size() {
+				return nil, errors.Errorf("malformed %v: digest for %q has the wrong length", cf.name, ce.Path)
+			}
+			listed[ce.Path] = true
+
+			data, ok := d.data.regularFileData("/" + ce.Path)
+			if !ok {
+				if !missing[ce.Path] {
+					missing[ce.Path] = true
+					v.Missing = append(v.Missing, ce.Path)
+				}
+				continue
+			}
+			h.Write(data)
+			if actual := hex.EncodeToString(h.Sum(nil)); actual != ce.Digest {
+				v.Mismatches = append(v.Mismatches, ChecksumMismatch{
+					Path: ce.Path, File: cf.name, Expected: ce.Digest, Actual: actual})
+			}
+		}
+	}
+	if len(v.Checked) == 0 {
+		return nil, ErrNoChecksums
+	}
+
+	conffiles, err := d.Conffiles()
+	if err != nil {
+		return nil, err
+	}
+	isConffile := make(map[string]bool)
+	for _, c := range conffiles {
+		isConffile[c.Path] = true
+	}
+
+	for name, e := range d.data.Contents {
+		if !e.IsReg() && e.Header.Typeflag != tar.TypeLink {
+			continue
+		}
+		if path := strings.TrimPrefix(name, "/"); !listed[path] && !isConffile[name] {
+			v.Extra = append(v.Extra, path)
+		}
+	}
+	sort.Strings(v.Missing)
+	sort.Strings(v.Extra)
+
+	return v, nil
+}
+
+// regularFileData returns the contents of the regular file with the given name, following hard links.
+func (t Tarball) regularFileData(name string) ([]byte, bool) {
+	e, ok := t.Get(name)
+	if ok && e.Header.Typeflag == tar.TypeLink {
+		// Hard link targets are stored like entry names ("./usr/bin/foo").
+		e, ok = t.Get(strings.TrimPrefix(e.Header.Linkname, "."))
+	}
+	if !ok || !e.IsReg() {
+		return nil, false
+	}
+	return e.Data, true
+}
@@ -0,0 +1,78 @@
+package debfile
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseChecksums(t *testing.T) {
+	entries, err := ParseChecksums(strings.NewReader(
+		"5D41402ABC4B2A76B9719D911017C592  usr/bin/foo\n" +
+			"d41d8cd98f00b204e9800998ecf8427e *usr/share/doc/foo/name with spaces\n"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []ChecksumEntry{
+		{Path: "usr/bin/foo", Digest: "5d41402abc4b2a76b9719d911017c592"},
+		{Path: "usr/share/doc/foo/name with spaces", Digest: "d41d8cd98f00b204e9800998ecf8427e"},
+	}, entries)
+
+	_, err = ParseChecksums(strings.NewReader("not-hex  usr/bin/foo\n"))
+	assert.Error(t, err)
+	_, err = ParseChecksums(strings.NewReader("d41d8cd98f00b204e9800998ecf8427e\n"))
+	assert.Error(t, err)
+}
+
+func TestVerify(t *testing.T) {
+	data := []testEntry{
+		{"/", ""}, {"/usr/", ""}, {"/usr/bin/", ""},
+		{"/usr/bin/foo", "hello"},
+		{"/usr/bin/bar", "world"},
+		{"/usr/bin/baz -> foo", ""},
+		{"/etc/", ""}, {"/etc/foo.conf", "x=1\n"},
+	}
+
+	d := loadTestDeb(t, []testEntry{
+		{"/conffiles", "/etc/foo.conf\n"},
+		{"/md5sums", "5d41402abc4b2a76b9719d911017c592  usr/bin/foo\n7d793037a0760186574b0282f2f435e7  usr/bin/bar\n"},
+		{"/sha256sums", "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824  usr/bin/foo\n"},
+	}, data)
+	v, err := d.Verify()
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"md5sums", "sha256sums"}, v.Checked)
+		assert.True(t, v.OK())
+		assert.NoError(t, v.Err())
+	}
+
+	d = loadTestDeb(t, []testEntry{
+		{"/md5sums", "00000000000000000000000000000000  usr/bin/foo\n" +
+			"d41d8cd98f00b204e9800998ecf8427e  usr/bin/gone\n"},
+		{"/sha256sums", "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855  usr/bin/gone\n"},
+	}, data)
+	v, err = d.Verify()
+	if assert.NoError(t, err) {
+		assert.False(t, v.OK())
+		assert.Equal(t, []string{"usr/bin/gone"}, v.Missing)
+		assert.Equal(t, []string{"etc/foo.conf", "usr/bin/bar"}, v.Extra)
+		assert.Equal(t, []ChecksumMismatch{{
+			Path: "usr/bin/foo", File: "md5sums",
+			Expected: "00000000000000000000000000000000", Actual: "5d41402abc4b2a76b9719d911017c592",
+		}}, v.Mismatches)
+		assert.Error(t, v.Err())
+	}
+
+	// A digest of the wrong length is an error in the checksum file rather than a mismatch.
+	for _, cf := range []string{"/md5sums", "/sha256sums"} {
+		d = loadTestDeb(t, []testEntry{
+			{cf, "5d41402abc4b2a76b9719d911017c59200  usr/bin/foo\n"},
+		}, data)
+		_, err = d.Verify()
+		assert.Error(t, err, cf)
+	}
+
+	d = loadTestDeb(t, []testEntry{{"/control", "Package: foo\n"}}, data)
+	_, err = d.Verify()
+	assert.Equal(t, ErrNoChecksums, err)
+}
@@ -0,0 +1,276 @@
+package debgraph
+
+import (
+	"sort"
+
+	"github.com/kelleyk/godebian/debcontrol"
+	"github.com/kelleyk/godebian/debrelation"
+	"github.com/kelleyk/godebian/debresolve"
+)
+
+// Edge records that From has a relationship (of the given field) that To can satisfy.  For a group of alternatives,
+// there is one edge to each package that satisfies any alternative.
+type Edge struct {
+	From     *debcontrol.BinaryPackage
+	To       *debcontrol.BinaryPackage
+	Field    string
+	Relation debrelation.Alternatives
+}
+
+type Options struct {
+	// Fields lists the relationship fields that produce edges.  If empty, Depends and Pre-Depends are used.
+	Fields []string
+}
+
+// Graph is a dependency graph over every package in a universe.
+type Graph struct {
+	u     *debresolve.Universe
+	out   map[*debcontrol.BinaryPackage][]Edge
+	in    map[*debcontrol.BinaryPackage][]Edge
+	nodes []*debcontrol.BinaryPackage
+}
+
+func New(u *debresolve.Universe, opts Options) *Graph {
+	fields := opts.Fields
+	if len(fields) == 0 {
+		fields = []string{"Pre-Depends", "Depends"}
+	}
+
+	g := &Graph{
+		u:     u,
+		out:   make(map[*debcontrol.BinaryPackage][]Edge),
+		in:    make(map[*debcontrol.BinaryPackage][]Edge),
+		nodes: append([]*debcontrol.BinaryPackage(nil), u.Packages()...),
+	}
+	sortPackages(g.nodes)
+
+	for _, p := range g.nodes {
+		for _, field := range fields {
+			for _, alts := range relationField(p, field) {
+				for _, q := range u.WhatProvidesAny(alts, p.Architecture) {
+					e := Edge{From: p, To: q, Field: field, Relation: alts}
+					g.out[p] = append(g.out[p], e)
+					g.in[q] = append(g.in[q], e)
+				}
+			}
+		}
+	}
+
+	return g
+}
+
+func relationField(p *debcontrol.BinaryPackage, field string) debrelation.Relations {
+	switch field {
+	case "Depends":
+		return p.Depends
+	case "Pre-Depends":
+		return p.PreDepends
+	case "Recommends":
+		return p.Recommends
+	case "Suggests":
+		return p.Suggests
+	case "Enhances":
+		return p.Enhances
+	default:
+		return nil
+	}
+}
+
+func sortPackages(ps []*debcontrol.BinaryPackage) {
+	sort.SliceStable(ps, func(i, j int) bool {
+		a, b := ps[i], ps[j]
+		if a.Package != b.Package {
+			return a.Package < b.Package
+		}
+		if a.Architecture != b.Architecture {
+			return a.Architecture < b.Architecture
+		}
+		return a.Version.GreaterThan(b.Version)
+	})
+}
+
+// Packages returns every node in the graph, sorted by name, architecture and then version (highest first).
+func (g *Graph) Packages() []*debcontrol.BinaryPackage {
+	return g.nodes
+}
+
+// Edges returns every edge in the graph, grouped by source package.
+func (g *Graph) Edges() []Edge {
+	var es []Edge
+	for _, p := range g.nodes {
+		es = append(es, g.out[p]...)
+	}
+	return es
+}
+
+func uniqueTargets(es []Edge, reverse bool) []*debcontrol.BinaryPackage {
+	var ps []*debcontrol.BinaryPackage
+	seen := make(map[*debcontrol.BinaryPackage]bool)
+	for _, e := range es {
+		p := e.To
+		if reverse {
+			p = e.From
+		}
+		if !seen[p] {
+			ps = append(ps, p)
+			seen[p] = true
+		}
+	}
+	sortPackages(ps)
+	return ps
+}
+
+// Dependencies returns the packages that can satisfy p's relationships.
+func (g *Graph) Dependencies(p *debcontrol.BinaryPackage) []*debcontrol.BinaryPackage {
+	return uniqueTargets(g.out[p], false)
+}
+
+// ReverseDependencies returns the packages with relationships that p can satisfy.
+func (g *Graph) ReverseDependencies(p *debcontrol.BinaryPackage) []*debcontrol.BinaryPackage {
+	return uniqueTargets(g.in[p], true)
+}
+
+// TransitiveDependencies returns every package reachable from p, not including p itself (unless it lies on a cycle).
+func (g *Graph) TransitiveDependencies(p *debcontrol.BinaryPackage) []*debcontrol.BinaryPackage {
+	return g.reachable(p, g.Dependencies)
+}
+
+// TransitiveReverseDependencies returns every package from which p is reachable, not including p itself (unless it
+// lies on a cycle).
+func (g *Graph) TransitiveReverseDependencies(p *debcontrol.BinaryPackage) []*debcontrol.BinaryPackage {
+	return g.reachable(p, g.ReverseDependencies)
+}
+
+func (g *Graph) reachable(start *debcontrol.BinaryPackage,
+	next func(*debcontrol.BinaryPackage) []*debcontrol.BinaryPackage) []*debcontrol.BinaryPackage {
+
+	var ps []*debcontrol.BinaryPackage
+	seen := make(map[*debcontrol.BinaryPackage]bool)
+	queue := next(start)
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		if seen[p] {
+			continue
+		}
+		seen[p] = true
+		ps = append(ps, p)
+		queue = append(queue, next(p)...)
+	}
+	sortPackages(ps)
+	return ps
+}
+
+// Providers returns the packages that provide the given (usually virtual) package name.
+func (g *Graph) Providers(name string) []*debcontrol.BinaryPackage {
+	ps := g.u.Providers(name)
+	sortPackages(ps)
+	return ps
+}
+
+// Cycles returns the strongly connected components of the graph that contain a cycle: every component with more than
+// one package, plus any package that depends on itself.
+func (g *Graph) Cycles() [][]*debcontrol.BinaryPackage {
+	// Tarjan's algorithm.
+	var (
+		index   = make(map[*debcontrol.BinaryPackage]int)
+		lowlink = make(map[*debcontrol.BinaryPackage]int)
+		onStack = make(map[*debcontrol.BinaryPackage]bool)
+		stack   []*debcontrol.BinaryPackage
+		cycles  [][]*debcontrol.BinaryPackage
+		visit   func(p *debcontrol.BinaryPackage)
+	)
+	visit = func(p *debcontrol.BinaryPackage) {
+		index[p] = len(index)
+		lowlink[p] = index[p]
+		stack = append(stack, p)
+		onStack[p] = true
+
+		selfLoop := false
+		for _, q := range g.Dependencies(p) {
+			if q == p {
+				selfLoop = true
+			}
+			if _, ok := index[q]; !ok {
+				visit(q)
+				if lowlink[q] < lowlink[p] {
+					lowlink[p] = lowlink[q]
+				}
+			} else if onStack[q] && index[q] < lowlink[p] {
+				lowlink[p] = index[q]
+			}
+		}
+
+		if lowlink[p] == index[p] {
+			var scc []*debcontrol.BinaryPackage
+			for {
+				q := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[q] = false
+				scc = append(scc, q)
+				if q == p {
+					break
+				}
+			}
+			if len(scc) > 1 || selfLoop {
+				sortPackages(scc)
+				cycles = append(cycles, scc)
+			}
+		}
+	}
+	for _, p := range g.nodes {
+		if _, ok := index[p]; !ok {
+			visit(p)
+		}
+	}
+
+	sort.SliceStable(cycles, func(i, j int) bool { return cycles[i][0].String() < cycles[j][0].String() })
+	return cycles
+}
+
+// BrokenByRemoval returns the packages that would have some relationship that nothing can satisfy if the given
+// packages were removed from the universe, either directly or because a package they rely on would itself be broken.
+// This considers each relationship in isolation and ignores conflicts; use debresolve to check that a set of packages
+// is actually co-installable.
+func (g *Graph) BrokenByRemoval(removed ...*debcontrol.BinaryPackage) []*debcontrol.BinaryPackage {
+	gone := make(map[*debcontrol.BinaryPackage]bool)
+	for _, p := range removed {
+		gone[p] = true
+	}
+
+	var broken []*debcontrol.BinaryPackage
+	queue := append([]*debcontrol.BinaryPackage(nil), removed...)
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		for _, q := range g.ReverseDependencies(p) {
+			if !gone[q] && !g.satisfiable(q, gone) {
+				gone[q] = true
+				broken = append(broken, q)
+				queue = append(queue, q)
+			}
+		}
+	}
+
+	sortPackages(broken)
+	return broken
+}
+
+// satisfiable reports whether each of p's relationships (as represented by edges) still has a target that is not gone.
+func (g *Graph) satisfiable(p *debcontrol.BinaryPackage, gone map[*debcontrol.BinaryPackage]bool) bool {
+	type group struct {
+		field string
+		rel   string
+	}
+	ok := make(map[group]bool)
+	for _, e := range g.out[p] {
+		k := group{e.Field, e.Relation.String()}
+		ok[k] = ok[k] || !gone[e.To]
+	}
+	for _, v := range ok {
+		if !v {
+			return false
+		}
+	}
+	return true
+}
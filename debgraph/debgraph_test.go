@@ -0,0 +1,137 @@
+package debgraph
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/kelleyk/godebian/debcontrol"
+	"github.com/kelleyk/godebian/debresolve"
+	"github.com/stretchr/testify/assert"
+)
+
+const testPackages = `
+Package: app
+Version: 1.0
+Architecture: amd64
+Depends: libfoo, mail-transport-agent
+Recommends: app-doc
+
+Package: app-doc
+Version: 1.0
+Architecture: all
+
+Package: libfoo
+Version: 2.0
+Architecture: amd64
+Pre-Depends: libc6
+
+Package: libc6
+Version: 2.36
+Architecture: amd64
+Depends: libgcc-s1
+
+Package: libgcc-s1
+Version: 12.2
+Architecture: amd64
+Depends: libc6
+
+Package: postfix
+Version: 3.7
+Architecture: amd64
+Provides: mail-transport-agent
+Depends: libc6
+
+Package: exim4
+Version: 4.96
+Architecture: amd64
+Provides: mail-transport-agent
+
+Package: selfish
+Version: 1
+Architecture: amd64
+Depends: selfish
+`
+
+func testGraph(t *testing.T, opts Options) (*Graph, map[string]*debcontrol.BinaryPackage) {
+	ps, err := debcontrol.ParseParagraphs(strings.NewReader(testPackages))
+	if err != nil {
+		t.Fatal(err)
+	}
+	byName := make(map[string]*debcontrol.BinaryPackage)
+	var pkgs []*debcontrol.BinaryPackage
+	for _, p := range ps {
+		b, err := debcontrol.ParseBinaryPackage(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pkgs = append(pkgs, b)
+		byName[b.Package] = b
+	}
+	return New(debresolve.NewUniverse(pkgs), opts), byName
+}
+
+func names(ps []*debcontrol.BinaryPackage) []string {
+	ss := []string{}
+	for _, p := range ps {
+		ss = append(ss, p.Package)
+	}
+	return ss
+}
+
+func TestQueries(t *testing.T) {
+	g, pkgs := testGraph(t, Options{})
+
+	assert.Equal(t, []string{"exim4", "libfoo", "postfix"}, names(g.Dependencies(pkgs["app"])))
+	assert.Equal(t, []string{"exim4", "libc6", "libfoo", "libgcc-s1", "postfix"},
+		names(g.TransitiveDependencies(pkgs["app"])))
+	assert.Equal(t, []string{"libfoo", "libgcc-s1", "postfix"}, names(g.ReverseDependencies(pkgs["libc6"])))
+	assert.Equal(t, []string{"app", "libc6", "libfoo", "libgcc-s1", "postfix"},
+		names(g.TransitiveReverseDependencies(pkgs["libc6"])))
+	assert.Equal(t, []string{"exim4", "postfix"}, names(g.Providers("mail-transport-agent")))
+
+	cycles := g.Cycles()
+	if assert.Len(t, cycles, 2) {
+		assert.Equal(t, []string{"libc6", "libgcc-s1"}, names(cycles[0]))
+		assert.Equal(t, []string{"selfish"}, names(cycles[1]))
+	}
+
+	// Removing one MTA leaves the other to satisfy app.
+	assert.Equal(t, []string{}, names(g.BrokenByRemoval(pkgs["postfix"])))
+	assert.Equal(t, []string{"app"}, names(g.BrokenByRemoval(pkgs["postfix"], pkgs["exim4"])))
+	assert.Equal(t, []string{"app", "libc6", "libfoo", "postfix"}, names(g.BrokenByRemoval(pkgs["libgcc-s1"])))
+
+	g, pkgs = testGraph(t, Options{Fields: []string{"Recommends"}})
+	assert.Equal(t, []string{"app-doc"}, names(g.Dependencies(pkgs["app"])))
+	assert.Equal(t, []string{}, names(g.Dependencies(pkgs["libfoo"])))
+}
+
+func TestOutput(t *testing.T) {
+	g, _ := testGraph(t, Options{})
+
+	var buf bytes.Buffer
+	if assert.NoError(t, g.WriteDOT(&buf)) {
+		dot := buf.String()
+		assert.True(t, strings.HasPrefix(dot, "digraph packages {\n"))
+		assert.Contains(t, dot, "\t\"app_1.0_amd64\" [label=\"app\\n1.0\"];\n")
+		assert.Contains(t, dot, "\t\"app_1.0_amd64\" -> \"libfoo_2.0_amd64\";\n")
+		assert.Contains(t, dot, "\t\"libfoo_2.0_amd64\" -> \"libc6_2.36_amd64\" [label=\"Pre-Depends\"];\n")
+	}
+
+	data, err := json.Marshal(g)
+	if !assert.NoError(t, err) {
+		return
+	}
+	var decoded struct {
+		Nodes []map[string]string
+		Edges []map[string]string
+	}
+	if assert.NoError(t, json.Unmarshal(data, &decoded)) {
+		assert.Len(t, decoded.Nodes, 8)
+		assert.Equal(t, map[string]string{
+			"from": "app_1.0_amd64", "to": "exim4_4.96_amd64", "field": "Depends",
+			"relation": "mail-transport-agent",
+		}, decoded.Edges[1])
+	}
+}
@@ -0,0 +1,76 @@
+package debgraph
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// WriteDOT writes the graph in Graphviz DOT format.  Nodes are labelled with the package name and version; edges for
+// relationship fields other than Depends are labelled with the field name.
+func (g *Graph) WriteDOT(w io.Writer) error {
+	if _, err := fmt.Fprintln(w, "digraph packages {"); err != nil {
+		return err
+	}
+	for _, p := range g.nodes {
+		label := p.Package + "\n" + p.Version.String()
+		if _, err := fmt.Fprintf(w, "\t%s [label=%s];\n", strconv.Quote(p.String()), strconv.Quote(label)); err != nil {
+			return err
+		}
+	}
+	for _, e := range g.Edges() {
+		attrs := ""
+		if e.Field != "Depends" {
+			attrs = fmt.Sprintf(" [label=%s]", strconv.Quote(e.Field))
+		}
+		if _, err := fmt.Fprintf(w, "\t%s -> %s%s;\n", strconv.Quote(e.From.String()), strconv.Quote(e.To.String()),
+			attrs); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintln(w, "}")
+	return err
+}
+
+type jsonNode struct {
+	ID           string `json:"id"`
+	Package      string `json:"package"`
+	Version      string `json:"version"`
+	Architecture string `json:"architecture"`
+}
+
+type jsonEdge struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Field    string `json:"field"`
+	Relation string `json:"relation"`
+}
+
+type jsonGraph struct {
+	Nodes []jsonNode `json:"nodes"`
+	Edges []jsonEdge `json:"edges"`
+}
+
+// MarshalJSON encodes the graph as an object with "nodes" and "edges" lists.  Edges refer to nodes by their "id",
+// which has the form "name_version_architecture".
+func (g *Graph) MarshalJSON() ([]byte, error) {
+	jg := jsonGraph{Nodes: []jsonNode{}, Edges: []jsonEdge{}}
+	for _, p := range g.nodes {
+		jg.Nodes = append(jg.Nodes, jsonNode{
+			ID:           p.String(),
+			Package:      p.Package,
+			Version:      p.Version.String(),
+			Architecture: p.Architecture,
+		})
+	}
+	for _, e := range g.Edges() {
+		jg.Edges = append(jg.Edges, jsonEdge{
+			From:     e.From.String(),
+			To:       e.To.String(),
+			Field:    e.Field,
+			Relation: e.Relation.String(),
+		})
+	}
+	return json.Marshal(jg)
+}
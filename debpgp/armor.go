@@ -0,0 +1,136 @@
+package debpgp
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// armorBlock is a decoded block of ASCII armor, such as a "PGP PUBLIC KEY BLOCK".
+//
+// Ref.: RFC 4880, section 6
+type armorBlock struct {
+	Type string
+	Data []byte
+}
+
+// isArmored reports whether data looks like ASCII armor rather than binary packets.
+func isArmored(data []byte) bool {
+	return bytes.Contains(data, []byte("-----BEGIN PGP "))
+}
+
+// decodeArmor decodes every armored block in data.  Text outside of the blocks is ignored.
+func decodeArmor(data []byte) ([]armorBlock, error) {
+	var blocks []armorBlock
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], " \t")
+		if !strings.HasPrefix(line, "-----BEGIN ") || !strings.HasSuffix(line, "-----") {
+			continue
+		}
+		typ := strings.TrimSuffix(strings.TrimPrefix(line, "-----BEGIN "), "-----")
+		end := "-----END " + typ + "-----"
+
+		// Skip the armor headers, which end at the first empty line.
+		for i++; i < len(lines) && strings.TrimSpace(lines[i]) != ""; i++ {
+			if !strings.Contains(lines[i], ": ") {
+				return nil, errors.Errorf("malformed armor header in %v: %q", typ, lines[i])
+			}
+		}
+
+		var b64 strings.Builder
+		var checksum string
+		for i++; ; i++ {
+			if i >= len(lines) {
+				return nil, errors.Errorf("unterminated %v", typ)
+			}
+			line := strings.TrimSpace(lines[i])
+			if line == end {
+				break
+			}
+			if strings.HasPrefix(line, "=") && len(line) == 5 {
+				checksum = line[1:]
+				continue
+			}
+			b64.WriteString(line)
+		}
+
+		decoded, err := base64.StdEncoding.DecodeString(b64.String())
+		if err != nil {
+			return nil, errors.Wrapf(err, "malformed base64 in %v", typ)
+		}
+		if checksum != "" {
+			sum, err := base64.StdEncoding.DecodeString(checksum)
+			if err != nil || len(sum) != 3 {
+				return nil, errors.Errorf("malformed checksum in %v", typ)
+			}
+			if crc := crc24(decoded); byte(crc>>16) != sum[0] || byte(crc>>8) != sum[1] || byte(crc) != sum[2] {
+				return nil, errors.Errorf("checksum mismatch in %v", typ)
+			}
+		}
+		blocks = append(blocks, armorBlock{Type: typ, Data: decoded})
+	}
+	return blocks, nil
+}
+
+// crc24 computes the checksum that armor uses.
+//
+// Ref.: RFC 4880, section 6.1
+func crc24(data []byte) uint32 {
+	const (
+		init = 0xB704CE
+		poly = 0x1864CFB
+	)
+	crc := uint32(init)
+	for _, b := range data {
+		crc ^= uint32(b) << 16
+		for i := 0; i < 8; i++ {
+			crc <<= 1
+			if crc&0x1000000 != 0 {
+				crc ^= poly
+			}
+		}
+	}
+	return crc & 0xFFFFFF
+}
+
+// dearmor returns the binary packets in data, decoding the armored blocks of the given type if data is armored.
+func dearmor(data []byte, typ string) ([]byte, error) {
+	if !isArmored(data) {
+		return data, nil
+	}
+	blocks, err := decodeArmor(data)
+	if err != nil {
+		return nil, err
+	}
+	var out []byte
+	for _, b := range blocks {
+		if b.Type == typ {
+			out = append(out, b.Data...)
+		}
+	}
+	if out == nil {
+		return nil, errors.Errorf("no %v found", typ)
+	}
+	return out, nil
+}
+
+// encodeArmor armors data as a block of the given type, with a checksum.
+func encodeArmor(typ string, data []byte) []byte {
+	var b bytes.Buffer
+	b.WriteString("-----BEGIN " + typ + "-----\n\n")
+	enc := base64.StdEncoding.EncodeToString(data)
+	for len(enc) > 64 {
+		b.WriteString(enc[:64] + "\n")
+		enc = enc[64:]
+	}
+	if enc != "" {
+		b.WriteString(enc + "\n")
+	}
+	crc := crc24(data)
+	b.WriteString("=" + base64.StdEncoding.EncodeToString([]byte{byte(crc >> 16), byte(crc >> 8), byte(crc)}) + "\n")
+	b.WriteString("-----END " + typ + "-----\n")
+	return b.Bytes()
+}
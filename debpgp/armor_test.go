@@ -0,0 +1,40 @@
+package debpgp
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCRC24(t *testing.T) {
+	assert.Equal(t, uint32(0xB704CE), crc24(nil))
+	// The check value of CRC-24/OPENPGP.
+	assert.Equal(t, uint32(0x21CF02), crc24([]byte("123456789")))
+}
+
+func TestDecodeArmor(t *testing.T) {
+	blocks, err := decodeArmor([]byte("junk\n-----BEGIN PGP SIGNATURE-----\nComment: x\n\nAQID\n=Z2GT\n" +
+		"-----END PGP SIGNATURE-----\n-----BEGIN PGP MESSAGE-----\n\nBAU=\n-----END PGP MESSAGE-----\n"))
+	if assert.Nil(t, err) && assert.Len(t, blocks, 2) {
+		assert.Equal(t, armorBlock{Type: "PGP SIGNATURE", Data: []byte{1, 2, 3}}, blocks[0])
+		assert.Equal(t, armorBlock{Type: "PGP MESSAGE", Data: []byte{4, 5}}, blocks[1])
+	}
+
+	for _, s := range []string{
+		"-----BEGIN PGP SIGNATURE-----\n\nAQID\n=AAAA\n-----END PGP SIGNATURE-----\n",
+		"-----BEGIN PGP SIGNATURE-----\n\nAQID\n",
+		"-----BEGIN PGP SIGNATURE-----\n\nAQ!D\n-----END PGP SIGNATURE-----\n",
+		"-----BEGIN PGP SIGNATURE-----\nnot a header\n\nAQID\n-----END PGP SIGNATURE-----\n",
+	} {
+		_, err := decodeArmor([]byte(s))
+		assert.NotNil(t, err, s)
+	}
+
+	// A corrupted armored key fails its checksum.
+	key := readTestFile(t, "rsa.asc")
+	i := bytes.Index(key, []byte("\n\n")) + 10
+	key[i] ^= 'A' ^ 'B'
+	_, err = ReadKeyring(bytes.NewReader(key))
+	assert.NotNil(t, err)
+}
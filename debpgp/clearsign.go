@@ -0,0 +1,126 @@
+// Package debpgp verifies the OpenPGP signatures that protect APT repositories (InRelease and Release.gpg files)
+// against a keyring of trusted public keys, the way that apt(8) does with gpgv(1).  Only the parts of OpenPGP that
+// repositories use are implemented: v4 RSA, ECDSA and EdDSA keys and signatures.
+package debpgp
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ClearsignedMessage is an OpenPGP cleartext signed message, such as an InRelease file.  Decoding a message does not
+// verify it; see Keyring.VerifyClearsigned.
+//
+// Ref.: RFC 4880, section 7
+type ClearsignedMessage struct {
+	// Hashes lists the values of the "Hash" armor headers.
+	Hashes []string
+	// Text is the signed text with dash-escaping removed.  Each line, including the last, ends with "\n".
+	//
+	// The line break before the signature is part of the armor rather than of the text, but is included here since
+	// Debian control files (like most text files) end with one.
+	Text []byte
+	// Signature is the ASCII-armored signature block, including its BEGIN and END lines.
+	Signature []byte
+}
+
+const (
+	clearsignBegin     = "-----BEGIN PGP SIGNED MESSAGE-----"
+	clearsignSignature = "-----BEGIN PGP SIGNATURE-----"
+	clearsignEnd       = "-----END PGP SIGNATURE-----"
+)
+
+// DecodeClearsigned splits a cleartext signed message into its text and its signature.  As in apt's
+// SplitClearSignedFile, the message must contain exactly one signed section and nothing but whitespace around it.
+func DecodeClearsigned(data []byte) (*ClearsignedMessage, error) {
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	i := 0
+	next := func() (string, bool) {
+		if i >= len(lines) {
+			return "", false
+		}
+		i++
+		return lines[i-1], true
+	}
+
+	// Unlike GnuPG, which ignores anything outside of the signed section, apt only allows whitespace there, so that
+	// unsigned content cannot be smuggled in alongside a valid signature.
+	for {
+		line, ok := next()
+		if !ok {
+			return nil, errors.New("not a clearsigned message")
+		}
+		if strings.TrimRight(line, " \t") == clearsignBegin {
+			break
+		}
+		if strings.TrimSpace(line) != "" {
+			return nil, errors.Errorf("unexpected content before clearsigned message: %q", line)
+		}
+	}
+
+	msg := &ClearsignedMessage{}
+	for {
+		line, ok := next()
+		if !ok {
+			return nil, errors.New("clearsigned message ends in its armor headers")
+		}
+		if line == "" {
+			break
+		}
+		name, value, ok := strings.Cut(line, ": ")
+		if !ok {
+			return nil, errors.Errorf("malformed armor header in clearsigned message: %q", line)
+		}
+		if name == "Hash" {
+			for _, h := range strings.Split(value, ",") {
+				msg.Hashes = append(msg.Hashes, strings.TrimSpace(h))
+			}
+		}
+	}
+
+	var text bytes.Buffer
+	for {
+		line, ok := next()
+		if !ok {
+			return nil, errors.New("clearsigned message has no signature")
+		}
+		if strings.TrimRight(line, " \t") == clearsignSignature {
+			break
+		}
+		if strings.HasPrefix(line, "-") {
+			if !strings.HasPrefix(line, "- ") {
+				return nil, errors.Errorf("improperly dash-escaped line in clearsigned message: %q", line)
+			}
+			line = line[2:]
+		}
+		text.WriteString(line)
+		text.WriteByte('\n')
+	}
+	msg.Text = text.Bytes()
+
+	sig := []string{clearsignSignature}
+	for {
+		line, ok := next()
+		if !ok {
+			return nil, errors.New("clearsigned message has an unterminated signature")
+		}
+		sig = append(sig, line)
+		if strings.TrimRight(line, " \t") == clearsignEnd {
+			break
+		}
+	}
+	msg.Signature = []byte(strings.Join(sig, "\n") + "\n")
+
+	for line, ok := next(); ok; line, ok = next() {
+		if strings.TrimRight(line, " \t") == clearsignBegin {
+			return nil, errors.New("more than one signed section in clearsigned message")
+		}
+		if strings.TrimSpace(line) != "" {
+			return nil, errors.Errorf("unexpected content after clearsigned message: %q", line)
+		}
+	}
+
+	return msg, nil
+}
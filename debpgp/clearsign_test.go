@@ -0,0 +1,59 @@
+package debpgp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeClearsigned(t *testing.T) {
+	msg, err := DecodeClearsigned([]byte(" \r\n\r\n-----BEGIN PGP SIGNED MESSAGE-----\r\nHash: SHA256, SHA512\r\n\r\n" +
+		"Suite: s\r\n- -----BEGIN\r\n- - x\r\n-----BEGIN PGP SIGNATURE-----\r\n\r\nabcd\r\n-----END PGP SIGNATURE-----\r\n"))
+	if assert.Nil(t, err) {
+		assert.Equal(t, []string{"SHA256", "SHA512"}, msg.Hashes)
+		assert.Equal(t, "Suite: s\n-----BEGIN\n- x\n", string(msg.Text))
+		assert.Equal(t, "-----BEGIN PGP SIGNATURE-----\n\nabcd\n-----END PGP SIGNATURE-----\n", string(msg.Signature))
+	}
+
+	// A dash-escaped empty line and a message without a Hash header.
+	msg, err = DecodeClearsigned([]byte("-----BEGIN PGP SIGNED MESSAGE-----\n\n- \nSuite: s\n" +
+		"-----BEGIN PGP SIGNATURE-----\n\nabcd\n-----END PGP SIGNATURE-----\n"))
+	if assert.Nil(t, err) {
+		assert.Nil(t, msg.Hashes)
+		assert.Equal(t, "\nSuite: s\n", string(msg.Text))
+	}
+
+	for _, s := range []string{
+		"Suite: s\n",
+		"-----BEGIN PGP SIGNED MESSAGE-----\nHash: SHA256\n",
+		"-----BEGIN PGP SIGNED MESSAGE-----\nHash: SHA256\n\nSuite: s\n",
+		"-----BEGIN PGP SIGNED MESSAGE-----\nHash: SHA256\n\n-Suite: s\n-----BEGIN PGP SIGNATURE-----\n",
+		"-----BEGIN PGP SIGNED MESSAGE-----\nHash: SHA256\n\nSuite: s\n-----BEGIN PGP SIGNATURE-----\n\nabcd\n",
+		"-----BEGIN PGP SIGNED MESSAGE-----\nHash SHA256\n\nSuite: s\n-----BEGIN PGP SIGNATURE-----\n\nabcd\n" +
+			"-----END PGP SIGNATURE-----\n",
+		"-----BEGIN PGP SIGNED MESSAGE-----\nHash: SHA256\n\n-\n-----BEGIN PGP SIGNATURE-----\n\nabcd\n" +
+			"-----END PGP SIGNATURE-----\n",
+	} {
+		_, err := DecodeClearsigned([]byte(s))
+		assert.NotNil(t, err, s)
+	}
+}
+
+func TestDecodeClearsignedRejectsUnsignedContent(t *testing.T) {
+	const signed = "-----BEGIN PGP SIGNED MESSAGE-----\nHash: SHA256\n\nSuite: s\n" +
+		"-----BEGIN PGP SIGNATURE-----\n\nabcd\n-----END PGP SIGNATURE-----\n"
+	_, err := DecodeClearsigned([]byte(signed))
+	assert.Nil(t, err)
+
+	for _, s := range []string{
+		"Suite: evil\n" + signed,
+		"junk\n\n" + signed,
+		signed + "Suite: evil\n",
+		signed + "\n  \n-----BEGIN PGP SIGNATURE-----\n",
+		signed + signed,
+		signed + "\n" + signed,
+	} {
+		_, err := DecodeClearsigned([]byte(s))
+		assert.NotNil(t, err, s)
+	}
+}
@@ -0,0 +1,393 @@
+package debpgp
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Public key algorithms.
+//
+// Ref.: RFC 4880, section 9.1; RFC 6637
+const (
+	algoRSA         = 1
+	algoRSASignOnly = 3
+	algoDSA         = 17
+	algoECDSA       = 19
+	algoEdDSA       = 22
+)
+
+var (
+	oidP256    = []byte{0x2A, 0x86, 0x48, 0xCE, 0x3D, 0x03, 0x01, 0x07}
+	oidP384    = []byte{0x2B, 0x81, 0x04, 0x00, 0x22}
+	oidP521    = []byte{0x2B, 0x81, 0x04, 0x00, 0x23}
+	oidEd25519 = []byte{0x2B, 0x06, 0x01, 0x04, 0x01, 0xDA, 0x47, 0x0F, 0x01}
+)
+
+// PublicKey is a primary key or subkey from a keyring.
+type PublicKey struct {
+	// Fingerprint is the upper-case hexadecimal version 4 fingerprint of the key.
+	Fingerprint string
+	KeyID       uint64
+	Algorithm   byte
+	// Bits is the size of the key: the modulus size for RSA and the curve size for elliptic curve keys.
+	Bits    int
+	Created time.Time
+	// Expires is the zero time if the key does not expire.
+	Expires time.Time
+	Revoked bool
+	// CanSign is true if the key may make data signatures.
+	CanSign bool
+
+	// pub is an *rsa.PublicKey, *ecdsa.PublicKey or ed25519.PublicKey, or nil if the algorithm is not supported.
+	pub crypto.PublicKey
+	// body is the body of the key packet, which signatures over the key hash.
+	body []byte
+}
+
+// Entity is a primary key together with its user IDs and subkeys.
+type Entity struct {
+	Primary *PublicKey
+	Subkeys []*PublicKey
+	UserIDs []string
+}
+
+// Keyring is a set of public keys, such as those in a file named by a "signed-by" option.
+type Keyring struct {
+	Entities []*Entity
+}
+
+// ReadKeyring reads a keyring, either as binary OpenPGP packets (as in a ".gpg" file) or ASCII-armored (as in a ".asc"
+// file).  Only keys whose self-signatures verify are included.
+func ReadKeyring(r io.Reader) (*Keyring, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	data, err = dearmor(data, "PGP PUBLIC KEY BLOCK")
+	if err != nil {
+		return nil, err
+	}
+	packets, err := readPackets(data)
+	if err != nil {
+		return nil, err
+	}
+
+	kr := &Keyring{}
+	for len(packets) > 0 {
+		// Each transferable public key starts with a public key packet and runs up to the next one.
+		if packets[0].tag != tagPublicKey {
+			if packets[0].tag == tagSecretKey || packets[0].tag == tagSecretSubkey {
+				return nil, errors.New("keyring contains secret keys")
+			}
+			packets = packets[1:]
+			continue
+		}
+		n := 1
+		for n < len(packets) && packets[n].tag != tagPublicKey && packets[n].tag != tagSecretKey {
+			n++
+		}
+		if e, err := parseEntity(packets[:n]); err == nil {
+			kr.Entities = append(kr.Entities, e)
+		}
+		packets = packets[n:]
+	}
+	return kr, nil
+}
+
+// LoadKeyring reads and merges the keyrings in the named files.
+func LoadKeyring(paths ...string) (*Keyring, error) {
+	kr := &Keyring{}
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		k, err := ReadKeyring(f)
+		f.Close()
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read keyring %v", path)
+		}
+		kr.Entities = append(kr.Entities, k.Entities...)
+	}
+	return kr, nil
+}
+
+// Fingerprints returns the fingerprints of the primary keys in the keyring.
+func (kr *Keyring) Fingerprints() []string {
+	var fprs []string
+	for _, e := range kr.Entities {
+		fprs = append(fprs, e.Primary.Fingerprint)
+	}
+	return fprs
+}
+
+// parseEntity parses a transferable public key and checks its self-signatures.  The primary key must have at least
+// one valid self-signature; subkeys without a valid binding signature are dropped.
+//
+// Ref.: RFC 4880, section 11.1
+func parseEntity(packets []packet) (*Entity, error) {
+	primary, err := parsePublicKey(packets[0].body)
+	if err != nil {
+		return nil, err
+	}
+	e := &Entity{Primary: primary}
+
+	var selfSigs []*signature // the valid user ID certifications and direct key signatures
+	var subkey *PublicKey
+	var subkeySigs []*signature
+	var userID []byte // the user ID that the following signatures certify, or nil
+	inUserAttribute := false
+
+	finishSubkey := func() {
+		if subkey != nil && bindSubkey(primary, subkey, subkeySigs) {
+			e.Subkeys = append(e.Subkeys, subkey)
+		}
+		subkey, subkeySigs = nil, nil
+	}
+
+	for _, p := range packets[1:] {
+		switch p.tag {
+		case tagUserID:
+			finishSubkey()
+			userID, inUserAttribute = p.body, false
+		case tagUserAttribute:
+			finishSubkey()
+			userID, inUserAttribute = nil, true
+		case tagPublicSubkey:
+			finishSubkey()
+			userID, inUserAttribute = nil, false
+			if subkey, err = parsePublicKey(p.body); err != nil {
+				subkey = nil
+			}
+		case tagSignature:
+			s, err := parseSignature(p.body)
+			if err != nil {
+				continue
+			}
+			switch {
+			case subkey != nil:
+				subkeySigs = append(subkeySigs, s)
+			case inUserAttribute:
+			case userID != nil && s.sigType >= sigCertGeneric && s.sigType <= sigCertPositive:
+				if verifyKeySignature(s, primary, primary, nil, userID) {
+					selfSigs = append(selfSigs, s)
+					e.UserIDs = appendUnique(e.UserIDs, string(userID))
+				}
+			case userID == nil && s.sigType == sigDirectKey:
+				if verifyKeySignature(s, primary, primary, nil, nil) {
+					selfSigs = append(selfSigs, s)
+				}
+			case userID == nil && s.sigType == sigKeyRevocation:
+				if verifyKeySignature(s, primary, primary, nil, nil) {
+					primary.Revoked = true
+				}
+			}
+		}
+	}
+	finishSubkey()
+
+	if len(selfSigs) == 0 {
+		return nil, errors.Errorf("key %v has no valid self-signature", primary.Fingerprint)
+	}
+	// The most recent self-signature determines the key's expiration time and flags.
+	sort.SliceStable(selfSigs, func(i, j int) bool { return selfSigs[i].created.After(selfSigs[j].created) })
+	applyKeySignature(primary, selfSigs[0])
+	return e, nil
+}
+
+// bindSubkey checks the binding signatures of a subkey, applying the most recent valid one.  It reports false if the
+// subkey has no valid binding signature.
+func bindSubkey(primary, subkey *PublicKey, sigs []*signature) bool {
+	var binding *signature
+	for _, s := range sigs {
+		switch s.sigType {
+		case sigSubkeyBinding:
+			if !verifyKeySignature(s, primary, primary, subkey, nil) {
+				continue
+			}
+			// A signing subkey must also sign the primary key, so that nobody can claim somebody else's key as a
+			// signing subkey.
+			if s.hasKeyFlags && s.keyFlags&keyFlagSign != 0 {
+				if s.embedded == nil || s.embedded.sigType != sigPrimaryBinding ||
+					!verifyKeySignature(s.embedded, subkey, primary, subkey, nil) {
+					continue
+				}
+			}
+			if binding == nil || s.created.After(binding.created) {
+				binding = s
+			}
+		case sigSubkeyRevocation:
+			if verifyKeySignature(s, primary, primary, subkey, nil) {
+				subkey.Revoked = true
+			}
+		}
+	}
+	if binding == nil {
+		return false
+	}
+	applyKeySignature(subkey, binding)
+	// Without key flags, only a subkey that has cross-certified the primary key may sign.
+	if !binding.hasKeyFlags {
+		subkey.CanSign = binding.embedded != nil && verifyKeySignature(binding.embedded, subkey, primary, subkey, nil)
+	}
+	return true
+}
+
+// applyKeySignature sets a key's expiration time and capabilities from its self-signature or binding signature.
+func applyKeySignature(k *PublicKey, s *signature) {
+	if s.keyExpires != 0 {
+		k.Expires = k.Created.Add(s.keyExpires)
+	}
+	k.CanSign = !s.hasKeyFlags || s.keyFlags&keyFlagSign != 0
+	k.CanSign = k.CanSign && k.pub != nil
+}
+
+// verifyKeySignature checks a signature over a key (and, optionally, a subkey or a user ID) made by signer.
+func verifyKeySignature(s *signature, signer, primary, subkey *PublicKey, userID []byte) bool {
+	if s.hash == crypto.MD5 || signer.pub == nil {
+		return false
+	}
+	h, err := s.newHash()
+	if err != nil {
+		return false
+	}
+	writeKeyForHash(h, primary)
+	if subkey != nil {
+		writeKeyForHash(h, subkey)
+	}
+	if userID != nil {
+		var hdr [5]byte
+		hdr[0] = 0xB4
+		binary.BigEndian.PutUint32(hdr[1:], uint32(len(userID)))
+		h.Write(hdr[:])
+		h.Write(userID)
+	}
+	return s.verify(signer, h) == nil
+}
+
+func writeKeyForHash(w io.Writer, k *PublicKey) {
+	w.Write([]byte{0x99, byte(len(k.body) >> 8), byte(len(k.body))})
+	w.Write(k.body)
+}
+
+// parsePublicKey parses a version 4 public key or public subkey packet.  Keys that use algorithms that cannot be used
+// to verify signatures are returned with a nil pub.
+//
+// Ref.: RFC 4880, section 5.5.2
+func parsePublicKey(body []byte) (*PublicKey, error) {
+	c := &cursor{b: body}
+	if v := c.u8(); v != 4 {
+		if c.err != nil {
+			return nil, c.err
+		}
+		return nil, errors.Errorf("unsupported key version %d", v)
+	}
+	k := &PublicKey{Created: time.Unix(int64(c.u32()), 0), Algorithm: c.u8(), body: body}
+
+	switch k.Algorithm {
+	case algoRSA, algoRSASignOnly:
+		n, e := c.mpi(), c.mpi()
+		if c.err == nil {
+			if len(e) > 4 {
+				return nil, errors.New("RSA public exponent is too large")
+			}
+			k.Bits = bitLength(n)
+			k.pub = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		}
+	case algoECDSA:
+		oid := c.bytes(int(c.u8()))
+		point := c.mpi()
+		var curve elliptic.Curve
+		switch {
+		case bytes.Equal(oid, oidP256):
+			curve = elliptic.P256()
+		case bytes.Equal(oid, oidP384):
+			curve = elliptic.P384()
+		case bytes.Equal(oid, oidP521):
+			curve = elliptic.P521()
+		}
+		if c.err == nil && curve != nil {
+			// Unmarshal only accepts uncompressed points, which is the only form that RFC 6637 defines.
+			x, y := elliptic.Unmarshal(curve, point)
+			if x == nil || !curve.IsOnCurve(x, y) {
+				return nil, errors.New("malformed ECDSA public key")
+			}
+			k.Bits = curve.Params().BitSize
+			k.pub = &ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+		}
+	case algoEdDSA:
+		oid := c.bytes(int(c.u8()))
+		point := c.mpi()
+		if c.err == nil && bytes.Equal(oid, oidEd25519) {
+			if len(point) != 1+ed25519.PublicKeySize || point[0] != 0x40 {
+				return nil, errors.New("malformed Ed25519 public key")
+			}
+			k.Bits = 255
+			k.pub = ed25519.PublicKey(point[1:])
+		}
+	}
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	fpr := sha1.New()
+	writeKeyForHash(fpr, k)
+	sum := fpr.Sum(nil)
+	k.Fingerprint = strings.ToUpper(hex.EncodeToString(sum))
+	k.KeyID = binary.BigEndian.Uint64(sum[12:])
+	return k, nil
+}
+
+// verifyDigest checks the algorithm-specific values of a signature over digest.
+func (k *PublicKey) verifyDigest(h crypto.Hash, digest []byte, mpis [][]byte) error {
+	var ok bool
+	switch pub := k.pub.(type) {
+	case *rsa.PublicKey:
+		sig := make([]byte, pub.Size())
+		if len(mpis[0]) > len(sig) {
+			return errors.New("RSA signature is too large")
+		}
+		copy(sig[len(sig)-len(mpis[0]):], mpis[0])
+		ok = rsa.VerifyPKCS1v15(pub, h, digest, sig) == nil
+	case *ecdsa.PublicKey:
+		ok = ecdsa.Verify(pub, digest, new(big.Int).SetBytes(mpis[0]), new(big.Int).SetBytes(mpis[1]))
+	case ed25519.PublicKey:
+		if len(mpis[0]) > 32 || len(mpis[1]) > 32 {
+			return errors.New("Ed25519 signature is too large")
+		}
+		sig := make([]byte, ed25519.SignatureSize)
+		copy(sig[32-len(mpis[0]):32], mpis[0])
+		copy(sig[64-len(mpis[1]):], mpis[1])
+		ok = ed25519.Verify(pub, digest, sig)
+	default:
+		return errors.Errorf("key %v uses unsupported public key algorithm %d", k.Fingerprint, k.Algorithm)
+	}
+	if !ok {
+		return errors.New("bad signature")
+	}
+	return nil
+}
+
+func appendUnique(ss []string, s string) []string {
+	for _, x := range ss {
+		if x == s {
+			return ss
+		}
+	}
+	return append(ss, s)
+}
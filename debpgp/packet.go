@@ -0,0 +1,215 @@
+package debpgp
+
+import (
+	"encoding/binary"
+	"math/bits"
+
+	"github.com/pkg/errors"
+)
+
+// Packet tags.
+//
+// Ref.: RFC 4880, section 4.3
+const (
+	tagSignature     = 2
+	tagSecretKey     = 5
+	tagPublicKey     = 6
+	tagSecretSubkey  = 7
+	tagUserID        = 13
+	tagPublicSubkey  = 14
+	tagUserAttribute = 17
+)
+
+type packet struct {
+	tag  int
+	body []byte
+}
+
+var errTruncated = errors.New("truncated OpenPGP packet")
+
+// readPackets splits data into packets, in either the old or the new packet format.
+//
+// Ref.: RFC 4880, section 4.2
+func readPackets(data []byte) ([]packet, error) {
+	var packets []packet
+	for len(data) > 0 {
+		hdr := data[0]
+		data = data[1:]
+		if hdr&0x80 == 0 {
+			return nil, errors.Errorf("malformed OpenPGP packet header: %#02x", hdr)
+		}
+
+		var p packet
+		if hdr&0x40 == 0 {
+			// Old format.
+			p.tag = int(hdr>>2) & 0x0F
+			var n int
+			switch hdr & 0x03 {
+			case 0:
+				n = 1
+			case 1:
+				n = 2
+			case 2:
+				n = 4
+			case 3:
+				// Indeterminate length: the packet extends to the end of the data.
+				p.body, data = data, nil
+				packets = append(packets, p)
+				continue
+			}
+			if len(data) < n {
+				return nil, errTruncated
+			}
+			var length uint64
+			for _, b := range data[:n] {
+				length = length<<8 | uint64(b)
+			}
+			data = data[n:]
+			if uint64(len(data)) < length {
+				return nil, errTruncated
+			}
+			p.body, data = data[:length], data[length:]
+		} else {
+			// New format, which allows the body to be split into several parts.
+			p.tag = int(hdr & 0x3F)
+			for {
+				length, partial, n, err := newFormatLength(data)
+				if err != nil {
+					return nil, err
+				}
+				data = data[n:]
+				if uint64(len(data)) < length {
+					return nil, errTruncated
+				}
+				p.body = append(p.body, data[:length]...)
+				data = data[length:]
+				if !partial {
+					break
+				}
+			}
+		}
+		packets = append(packets, p)
+	}
+	return packets, nil
+}
+
+// newFormatLength decodes a new-format packet length, returning the length, whether it is a partial body length, and
+// the number of bytes that the length occupied.
+func newFormatLength(data []byte) (uint64, bool, int, error) {
+	if len(data) < 1 {
+		return 0, false, 0, errTruncated
+	}
+	switch o := data[0]; {
+	case o < 192:
+		return uint64(o), false, 1, nil
+	case o < 224:
+		if len(data) < 2 {
+			return 0, false, 0, errTruncated
+		}
+		return uint64(o-192)<<8 + uint64(data[1]) + 192, false, 2, nil
+	case o < 255:
+		return 1 << (o & 0x1F), true, 1, nil
+	default:
+		if len(data) < 5 {
+			return 0, false, 0, errTruncated
+		}
+		return uint64(binary.BigEndian.Uint32(data[1:5])), false, 5, nil
+	}
+}
+
+// cursor reads the fields of a packet body.  After the first error, every read returns zero values, so that a parser
+// need only check err at the end.
+type cursor struct {
+	b   []byte
+	err error
+}
+
+func (c *cursor) bytes(n int) []byte {
+	if c.err != nil {
+		return nil
+	}
+	if n < 0 || len(c.b) < n {
+		c.err = errTruncated
+		return nil
+	}
+	out := c.b[:n]
+	c.b = c.b[n:]
+	return out
+}
+
+func (c *cursor) u8() byte {
+	b := c.bytes(1)
+	if b == nil {
+		return 0
+	}
+	return b[0]
+}
+
+func (c *cursor) u16() int {
+	b := c.bytes(2)
+	if b == nil {
+		return 0
+	}
+	return int(binary.BigEndian.Uint16(b))
+}
+
+func (c *cursor) u32() uint32 {
+	b := c.bytes(4)
+	if b == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint32(b)
+}
+
+// mpi reads a multiprecision integer, returning its big-endian magnitude without leading zero bytes.
+//
+// Ref.: RFC 4880, section 3.2
+func (c *cursor) mpi() []byte {
+	n := c.u16()
+	b := c.bytes((n + 7) / 8)
+	for len(b) > 0 && b[0] == 0 {
+		b = b[1:]
+	}
+	return b
+}
+
+// rest returns the unread part of the body.
+func (c *cursor) rest() []byte {
+	return c.bytes(len(c.b))
+}
+
+// bitLength returns the number of significant bits in a big-endian magnitude.
+func bitLength(b []byte) int {
+	for len(b) > 0 && b[0] == 0 {
+		b = b[1:]
+	}
+	if len(b) == 0 {
+		return 0
+	}
+	return (len(b)-1)*8 + bits.Len8(b[0])
+}
+
+// appendPacket appends a new-format packet.
+func appendPacket(b []byte, tag int, body []byte) []byte {
+	b = append(b, 0xC0|byte(tag))
+	switch n := len(body); {
+	case n < 192:
+		b = append(b, byte(n))
+	case n < 8384:
+		n -= 192
+		b = append(b, byte(n>>8)+192, byte(n))
+	default:
+		b = append(b, 0xFF)
+		b = binary.BigEndian.AppendUint32(b, uint32(n))
+	}
+	return append(b, body...)
+}
+
+// appendMPI appends a multiprecision integer given its big-endian magnitude.
+func appendMPI(b, m []byte) []byte {
+	for len(m) > 0 && m[0] == 0 {
+		m = m[1:]
+	}
+	b = binary.BigEndian.AppendUint16(b, uint16(bitLength(m)))
+	return append(b, m...)
+}
@@ -0,0 +1,302 @@
+package debpgp
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/asn1"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// New signatures use SHA-512, which has the OpenPGP hash algorithm ID 10.
+const (
+	signingHash   = crypto.SHA512
+	signingHashID = 10
+)
+
+// SigningKey is a key that can make signatures, such as a repository's archive signing key.
+type SigningKey struct {
+	// Key is the key that makes signatures, which may be a subkey.
+	Key *PublicKey
+	// Entity is the certificate that Key belongs to; this is what verifiers need in their keyrings.
+	Entity *Entity
+
+	signer crypto.Signer
+}
+
+// NewSigningKey pairs a key from a keyring with a crypto.Signer (which might, for example, be backed by a hardware
+// token) that holds the corresponding private key.
+func NewSigningKey(e *Entity, k *PublicKey, signer crypto.Signer) (*SigningKey, error) {
+	pub, ok := signer.Public().(interface{ Equal(crypto.PublicKey) bool })
+	if !ok || k.pub == nil || !pub.Equal(k.pub) {
+		return nil, errors.Errorf("signer does not hold the private key for key %v", k.Fingerprint)
+	}
+	if !k.CanSign {
+		return nil, errors.Errorf("key %v is not a signing key", k.Fingerprint)
+	}
+	return &SigningKey{Key: k, Entity: e, signer: signer}, nil
+}
+
+// ReadSigningKey reads an unencrypted secret key, as exported by "gpg --export-secret-keys" (armored or not) for a key
+// without a passphrase.  If the key has signing subkeys, the most recently created one that is usable is chosen, as gpg
+// does; otherwise the primary key is used.
+func ReadSigningKey(r io.Reader) (*SigningKey, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	data, err = dearmor(data, "PGP PRIVATE KEY BLOCK")
+	if err != nil {
+		return nil, err
+	}
+	packets, err := readPackets(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(packets) == 0 || packets[0].tag != tagSecretKey {
+		return nil, errors.New("no secret key found")
+	}
+
+	// Convert the secret key packets to public key packets so that the certificate can be checked like any other,
+	// remembering the private keys.
+	signers := make(map[string]crypto.Signer)
+	var public []packet
+	for i, p := range packets {
+		if p.tag == tagSecretKey && i > 0 {
+			break
+		}
+		switch p.tag {
+		case tagSecretKey, tagSecretSubkey:
+			body, signer, err := parseSecretKey(p.body)
+			if err != nil {
+				return nil, err
+			}
+			if signer != nil {
+				signers[string(body)] = signer
+			}
+			tag := tagPublicKey
+			if p.tag == tagSecretSubkey {
+				tag = tagPublicSubkey
+			}
+			public = append(public, packet{tag: tag, body: body})
+		default:
+			public = append(public, p)
+		}
+	}
+	e, err := parseEntity(public)
+	if err != nil {
+		return nil, err
+	}
+
+	var k *PublicKey
+	for _, sub := range e.Subkeys {
+		usable := sub.CanSign && !sub.Revoked && signers[string(sub.body)] != nil
+		if usable && (k == nil || sub.Created.After(k.Created)) {
+			k = sub
+		}
+	}
+	if k == nil {
+		k = e.Primary
+	}
+	if signers[string(k.body)] == nil {
+		return nil, errors.Errorf("no private key for key %v", k.Fingerprint)
+	}
+	return NewSigningKey(e, k, signers[string(k.body)])
+}
+
+// LoadSigningKey reads a signing key from the named file; see ReadSigningKey.
+func LoadSigningKey(path string) (*SigningKey, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	k, err := ReadSigningKey(f)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read signing key %v", path)
+	}
+	return k, nil
+}
+
+// parseSecretKey parses a version 4 secret key or secret subkey packet, returning the body of the corresponding public
+// key packet and the private key.  The private key is nil if the algorithm is not one that can sign.
+//
+// Ref.: RFC 4880, section 5.5.3
+func parseSecretKey(body []byte) ([]byte, crypto.Signer, error) {
+	c := &cursor{b: body}
+	c.bytes(5) // version and creation time
+	algo := c.u8()
+	switch algo {
+	case algoRSA, algoRSASignOnly:
+		c.mpi()
+		c.mpi()
+	case algoECDSA, algoEdDSA:
+		c.bytes(int(c.u8()))
+		c.mpi()
+	default:
+		return nil, nil, errors.Errorf("unsupported secret key algorithm %d", algo)
+	}
+	if c.err != nil {
+		return nil, nil, c.err
+	}
+	pubBody := body[:len(body)-len(c.b)]
+	k, err := parsePublicKey(pubBody)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if usage := c.u8(); usage != 0 {
+		if c.err != nil {
+			return nil, nil, c.err
+		}
+		return nil, nil, errors.Errorf("secret key %v is encrypted or not available", k.Fingerprint)
+	}
+
+	var signer crypto.Signer
+	switch pub := k.pub.(type) {
+	case *rsa.PublicKey:
+		d, p, q := c.mpi(), c.mpi(), c.mpi()
+		c.mpi() // u, which crypto/rsa computes itself
+		if c.err == nil {
+			priv := &rsa.PrivateKey{
+				PublicKey: *pub,
+				D:         new(big.Int).SetBytes(d),
+				Primes:    []*big.Int{new(big.Int).SetBytes(p), new(big.Int).SetBytes(q)},
+			}
+			if err := priv.Validate(); err != nil {
+				return nil, nil, errors.Wrapf(err, "malformed RSA secret key %v", k.Fingerprint)
+			}
+			priv.Precompute()
+			signer = priv
+		}
+	case *ecdsa.PublicKey:
+		d := c.mpi()
+		if c.err == nil {
+			priv := &ecdsa.PrivateKey{PublicKey: *pub, D: new(big.Int).SetBytes(d)}
+			// The secret scalar must be in [1, n) and must match the public point.
+			x, y := pub.Curve.ScalarBaseMult(d)
+			if priv.D.Sign() == 0 || priv.D.Cmp(pub.Curve.Params().N) >= 0 || x.Cmp(pub.X) != 0 || y.Cmp(pub.Y) != 0 {
+				return nil, nil, errors.Errorf("malformed ECDSA secret key %v", k.Fingerprint)
+			}
+			signer = priv
+		}
+	case ed25519.PublicKey:
+		seed := c.mpi()
+		if c.err == nil {
+			if len(seed) > ed25519.SeedSize {
+				return nil, nil, errors.Errorf("malformed Ed25519 secret key %v", k.Fingerprint)
+			}
+			signer = ed25519.NewKeyFromSeed(append(make([]byte, ed25519.SeedSize-len(seed)), seed...))
+		}
+	}
+	if c.err != nil {
+		return nil, nil, c.err
+	}
+	return pubBody, signer, nil
+}
+
+// sign makes a signature packet of the given type and creation time over the data that write produces.
+func (k *SigningKey) sign(sigType byte, created time.Time, write func(w io.Writer)) ([]byte, error) {
+	var hashed []byte
+	hashed = append(hashed, 5, subpacketCreationTime)
+	hashed = binary.BigEndian.AppendUint32(hashed, uint32(created.Unix()))
+	hashed = append(hashed, 22, subpacketIssuerFingerprint, 4)
+	fpr, err := hex.DecodeString(k.Key.Fingerprint)
+	if err != nil {
+		return nil, err
+	}
+	hashed = append(hashed, fpr...)
+	unhashed := []byte{9, subpacketIssuer}
+	unhashed = binary.BigEndian.AppendUint64(unhashed, k.Key.KeyID)
+
+	body := []byte{4, sigType, k.Key.Algorithm, signingHashID}
+	body = binary.BigEndian.AppendUint16(body, uint16(len(hashed)))
+	body = append(body, hashed...)
+
+	h := signingHash.New()
+	write(h)
+	h.Write(body)
+	var trailer [6]byte
+	trailer[0], trailer[1] = 4, 0xFF
+	binary.BigEndian.PutUint32(trailer[2:], uint32(len(body)))
+	h.Write(trailer[:])
+	digest := h.Sum(nil)
+
+	body = binary.BigEndian.AppendUint16(body, uint16(len(unhashed)))
+	body = append(body, unhashed...)
+	body = append(body, digest[:2]...)
+
+	switch k.signer.Public().(type) {
+	case *rsa.PublicKey:
+		sig, err := k.signer.Sign(rand.Reader, digest, signingHash)
+		if err != nil {
+			return nil, err
+		}
+		body = appendMPI(body, sig)
+	case *ecdsa.PublicKey:
+		der, err := k.signer.Sign(rand.Reader, digest, signingHash)
+		if err != nil {
+			return nil, err
+		}
+		var rs struct{ R, S *big.Int }
+		if _, err := asn1.Unmarshal(der, &rs); err != nil {
+			return nil, errors.Wrap(err, "malformed ECDSA signature")
+		}
+		body = appendMPI(appendMPI(body, rs.R.Bytes()), rs.S.Bytes())
+	case ed25519.PublicKey:
+		// EdDSA signs the digest itself, as the message.
+		sig, err := k.signer.Sign(rand.Reader, digest, crypto.Hash(0))
+		if err != nil {
+			return nil, err
+		}
+		body = appendMPI(appendMPI(body, sig[:32]), sig[32:])
+	default:
+		return nil, errors.Errorf("key %v uses unsupported public key algorithm %d", k.Key.Fingerprint, k.Key.Algorithm)
+	}
+	return appendPacket(nil, tagSignature, body), nil
+}
+
+// SignDetached returns an armored detached signature over data, as in a Release.gpg file, made at the given time.
+func (k *SigningKey) SignDetached(data []byte, created time.Time) ([]byte, error) {
+	sig, err := k.sign(sigBinary, created, func(w io.Writer) { w.Write(data) })
+	if err != nil {
+		return nil, err
+	}
+	return encodeArmor("PGP SIGNATURE", sig), nil
+}
+
+// Clearsign returns text as a cleartext signed message, as in an InRelease file, with a signature made at the given
+// time.  Line endings are normalized to "\n" and a final line break is added if text lacks one; DecodeClearsigned
+// returns the text in the same form.
+func (k *SigningKey) Clearsign(text []byte, created time.Time) ([]byte, error) {
+	text = bytes.ReplaceAll(text, []byte("\r\n"), []byte("\n"))
+	if len(text) > 0 && text[len(text)-1] != '\n' {
+		text = append(text, '\n')
+	}
+	m := &ClearsignedMessage{Text: text}
+	sig, err := k.sign(sigText, created, func(w io.Writer) { w.Write(m.signedText()) })
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	b.WriteString("-----BEGIN PGP SIGNED MESSAGE-----\nHash: SHA512\n\n")
+	for _, line := range bytes.SplitAfter(text, []byte("\n")) {
+		if bytes.HasPrefix(line, []byte("-")) {
+			b.WriteString("- ")
+		}
+		b.Write(line)
+	}
+	b.Write(encodeArmor("PGP SIGNATURE", sig))
+	return b.Bytes(), nil
+}
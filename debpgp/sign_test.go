@@ -0,0 +1,87 @@
+package debpgp
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSign(t *testing.T) {
+	kr := loadTestKeyring(t, "keyring.gpg", "subkey.asc")
+	text := []byte("Suite: stable\n-----BEGIN not really\ntrailing space   \nno final newline")
+
+	for _, tc := range []struct {
+		name        string
+		fingerprint string
+	}{
+		{"rsa-secret.asc", fprRSA},
+		{"ed25519-secret.asc", fprEd25519},
+		{"ecdsa-secret.asc", fprECDSA},
+		{"subkey-secret.asc", fprSubkey},
+	} {
+		k, err := LoadSigningKey("testdata/" + tc.name)
+		if !assert.Nil(t, err, tc.name) {
+			continue
+		}
+		assert.Equal(t, tc.fingerprint, k.Key.Fingerprint, tc.name)
+		created := k.Key.Created.Add(time.Minute)
+		p := Policy{Now: func() time.Time { return created.Add(time.Minute) }}
+
+		msg, err := k.Clearsign(text, created)
+		if assert.Nil(t, err, tc.name) {
+			payload, sig, err := kr.VerifyClearsigned(msg, p)
+			if assert.Nil(t, err, tc.name) {
+				assert.Equal(t, string(text)+"\n", string(payload), tc.name)
+				assert.Equal(t, tc.fingerprint, sig.Fingerprint, tc.name)
+				assert.True(t, created.Equal(sig.Created), tc.name)
+			}
+		}
+
+		detached, err := k.SignDetached(text, created)
+		if assert.Nil(t, err, tc.name) {
+			sig, err := kr.VerifyDetached(text, detached, p)
+			if assert.Nil(t, err, tc.name) {
+				assert.Equal(t, tc.fingerprint, sig.Fingerprint, tc.name)
+				assert.True(t, created.Equal(sig.Created), tc.name)
+			}
+			_, err = kr.VerifyDetached(append(text, '\n'), detached, p)
+			assert.NotNil(t, err, tc.name)
+		}
+	}
+
+	_, err := ReadSigningKey(bytes.NewReader(readTestFile(t, "rsa.asc")))
+	assert.NotNil(t, err)
+	_, err = LoadSigningKey("testdata/nonexistent.asc")
+	assert.NotNil(t, err)
+}
+
+func TestNewSigningKey(t *testing.T) {
+	rsaKey, err := LoadSigningKey("testdata/rsa-secret.asc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	edKey, err := LoadSigningKey("testdata/ed25519-secret.asc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	k, err := NewSigningKey(rsaKey.Entity, rsaKey.Key, rsaKey.signer)
+	if assert.Nil(t, err) {
+		assert.Equal(t, fprRSA, k.Key.Fingerprint)
+	}
+	_, err = NewSigningKey(rsaKey.Entity, rsaKey.Key, edKey.signer)
+	assert.NotNil(t, err)
+}
+
+func TestEncodeArmor(t *testing.T) {
+	for _, n := range []int{0, 1, 47, 48, 49, 200} {
+		data := bytes.Repeat([]byte{0xA5}, n)
+		blocks, err := decodeArmor(encodeArmor("PGP SIGNATURE", data))
+		if assert.Nil(t, err, n) && assert.Len(t, blocks, 1, n) {
+			assert.Equal(t, "PGP SIGNATURE", blocks[0].Type)
+			assert.Equal(t, len(data), len(blocks[0].Data))
+		}
+	}
+}
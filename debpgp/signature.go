@@ -0,0 +1,251 @@
+package debpgp
+
+import (
+	"crypto"
+	"encoding/binary"
+	"hash"
+	"time"
+
+	// Register the hash functions that signatures may use.
+	_ "crypto/md5"
+	_ "crypto/sha1"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+
+	"github.com/pkg/errors"
+)
+
+// Signature types.
+//
+// Ref.: RFC 4880, section 5.2.1
+const (
+	sigBinary           = 0x00
+	sigText             = 0x01
+	sigCertGeneric      = 0x10
+	sigCertPositive     = 0x13
+	sigSubkeyBinding    = 0x18
+	sigPrimaryBinding   = 0x19
+	sigDirectKey        = 0x1F
+	sigKeyRevocation    = 0x20
+	sigSubkeyRevocation = 0x28
+)
+
+// Signature subpacket types.
+//
+// Ref.: RFC 4880, section 5.2.3.1
+const (
+	subpacketCreationTime      = 2
+	subpacketExpirationTime    = 3
+	subpacketKeyExpirationTime = 9
+	subpacketIssuer            = 16
+	subpacketKeyFlags          = 27
+	subpacketEmbeddedSignature = 32
+	subpacketIssuerFingerprint = 33
+)
+
+// keyFlagSign is the key flag that marks a key as usable for signing data.
+const keyFlagSign = 0x02
+
+// hashAlgorithms maps OpenPGP hash algorithm IDs to the corresponding hash functions.  RIPEMD-160 (3) is missing
+// because Go's standard library does not implement it.
+//
+// Ref.: RFC 4880, section 9.4
+var hashAlgorithms = map[byte]crypto.Hash{
+	1:  crypto.MD5,
+	2:  crypto.SHA1,
+	8:  crypto.SHA256,
+	9:  crypto.SHA384,
+	10: crypto.SHA512,
+	11: crypto.SHA224,
+}
+
+// hashNames maps hash functions to the names that "Hash" armor headers use for them.
+//
+// Ref.: RFC 4880, section 9.4
+var hashNames = map[crypto.Hash]string{
+	crypto.MD5:    "MD5",
+	crypto.SHA1:   "SHA1",
+	crypto.SHA256: "SHA256",
+	crypto.SHA384: "SHA384",
+	crypto.SHA512: "SHA512",
+	crypto.SHA224: "SHA224",
+}
+
+// signature is a parsed version 4 signature packet.
+type signature struct {
+	sigType byte
+	pubAlgo byte
+	hash    crypto.Hash
+
+	// hashedPart is the part of the packet that is hashed along with the signed data.
+	hashedPart []byte
+	left16     []byte
+	// mpis holds the algorithm-specific signature values.
+	mpis [][]byte
+
+	created     time.Time
+	expires     time.Duration // zero if the signature does not expire
+	keyExpires  time.Duration // zero if the key does not expire
+	keyFlags    byte
+	hasKeyFlags bool
+	issuerKeyID uint64
+	issuerFpr   []byte
+	embedded    *signature
+	// unknownCritical is set if a hashed subpacket that we do not understand is marked as critical, in which case the
+	// signature must be treated as invalid.
+	unknownCritical bool
+}
+
+func parseSignature(body []byte) (*signature, error) {
+	c := &cursor{b: body}
+	if v := c.u8(); v != 4 {
+		if c.err != nil {
+			return nil, c.err
+		}
+		return nil, errors.Errorf("unsupported signature version %d", v)
+	}
+	s := &signature{sigType: c.u8(), pubAlgo: c.u8()}
+	hashAlgo := c.u8()
+	hashed := c.bytes(c.u16())
+	unhashed := c.bytes(c.u16())
+	s.left16 = c.bytes(2)
+	if c.err != nil {
+		return nil, c.err
+	}
+	s.hashedPart = body[:len(body)-len(c.b)-2-len(unhashed)-2]
+
+	h, ok := hashAlgorithms[hashAlgo]
+	if !ok {
+		return nil, errors.Errorf("unsupported hash algorithm %d", hashAlgo)
+	}
+	s.hash = h
+
+	if err := s.parseSubpackets(hashed, true); err != nil {
+		return nil, err
+	}
+	if err := s.parseSubpackets(unhashed, false); err != nil {
+		return nil, err
+	}
+	if s.created.IsZero() {
+		return nil, errors.New("signature has no creation time")
+	}
+
+	switch s.pubAlgo {
+	case algoRSA, algoRSASignOnly:
+		s.mpis = [][]byte{c.mpi()}
+	case algoECDSA, algoEdDSA:
+		s.mpis = [][]byte{c.mpi(), c.mpi()}
+	default:
+		// Keep the rest so that the packet can at least be parsed; verification will fail.
+		s.mpis = [][]byte{c.rest()}
+	}
+	if c.err != nil {
+		return nil, c.err
+	}
+	return s, nil
+}
+
+// parseSubpackets parses a subpacket area.  Only the issuer subpackets are trusted when they are unhashed, and then
+// only as a hint as to which key to verify the signature with.
+func (s *signature) parseSubpackets(area []byte, hashed bool) error {
+	for len(area) > 0 {
+		length, n, err := subpacketLength(area)
+		if err != nil {
+			return err
+		}
+		area = area[n:]
+		if length == 0 || uint64(len(area)) < length {
+			return errTruncated
+		}
+		sp := area[:length]
+		area = area[length:]
+
+		typ, data := sp[0]&0x7F, sp[1:]
+		critical := sp[0]&0x80 != 0
+		if !hashed && typ != subpacketIssuer && typ != subpacketIssuerFingerprint && typ != subpacketEmbeddedSignature {
+			continue
+		}
+		switch {
+		case typ == subpacketCreationTime && len(data) == 4:
+			s.created = time.Unix(int64(binary.BigEndian.Uint32(data)), 0)
+		case typ == subpacketExpirationTime && len(data) == 4:
+			s.expires = time.Duration(binary.BigEndian.Uint32(data)) * time.Second
+		case typ == subpacketKeyExpirationTime && len(data) == 4:
+			s.keyExpires = time.Duration(binary.BigEndian.Uint32(data)) * time.Second
+		case typ == subpacketIssuer && len(data) == 8:
+			s.issuerKeyID = binary.BigEndian.Uint64(data)
+		case typ == subpacketIssuerFingerprint && len(data) == 21 && data[0] == 4:
+			s.issuerFpr = data[1:]
+		case typ == subpacketKeyFlags && len(data) >= 1:
+			s.keyFlags, s.hasKeyFlags = data[0], true
+		case typ == subpacketEmbeddedSignature:
+			embedded, err := parseSignature(data)
+			if err != nil {
+				return errors.Wrap(err, "malformed embedded signature")
+			}
+			s.embedded = embedded
+		default:
+			if critical && hashed {
+				s.unknownCritical = true
+			}
+		}
+	}
+	return nil
+}
+
+// subpacketLength decodes the length of a signature subpacket, returning the length and the number of bytes that it
+// occupied.  It resembles a new-format packet length, but there are no partial lengths: a first octet of 192 to 254
+// always starts a two-octet length.
+//
+// Ref.: RFC 4880, section 5.2.3.1
+func subpacketLength(data []byte) (uint64, int, error) {
+	if len(data) < 1 {
+		return 0, 0, errTruncated
+	}
+	switch o := data[0]; {
+	case o < 192:
+		return uint64(o), 1, nil
+	case o < 255:
+		if len(data) < 2 {
+			return 0, 0, errTruncated
+		}
+		return uint64(o-192)<<8 + uint64(data[1]) + 192, 2, nil
+	default:
+		if len(data) < 5 {
+			return 0, 0, errTruncated
+		}
+		return uint64(binary.BigEndian.Uint32(data[1:5])), 5, nil
+	}
+}
+
+// newHash returns a hash.Hash for the signature's hash algorithm, or an error if it is not available.
+func (s *signature) newHash() (hash.Hash, error) {
+	if !s.hash.Available() {
+		return nil, errors.Errorf("hash algorithm %v is not available", s.hash)
+	}
+	return s.hash.New(), nil
+}
+
+// verify checks the signature over the data already written to h, which must have come from newHash.
+func (s *signature) verify(k *PublicKey, h hash.Hash) error {
+	if s.unknownCritical {
+		return errors.New("signature has an unknown critical subpacket")
+	}
+	if s.pubAlgo != k.Algorithm {
+		return errors.Errorf("signature was made with algorithm %d, but key %v has algorithm %d", s.pubAlgo,
+			k.Fingerprint, k.Algorithm)
+	}
+
+	// The trailer covers the hashed part of the signature packet itself.
+	h.Write(s.hashedPart)
+	var trailer [6]byte
+	trailer[0], trailer[1] = 4, 0xFF
+	binary.BigEndian.PutUint32(trailer[2:], uint32(len(s.hashedPart)))
+	h.Write(trailer[:])
+	digest := h.Sum(nil)
+
+	if digest[0] != s.left16[0] || digest[1] != s.left16[1] {
+		return errors.New("signature digest does not match")
+	}
+	return k.verifyDigest(s.hash, digest, s.mpis)
+}
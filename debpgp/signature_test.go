@@ -0,0 +1,48 @@
+package debpgp
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubpacketLength(t *testing.T) {
+	for _, tt := range []struct {
+		data   []byte
+		length uint64
+		n      int
+	}{
+		{[]byte{0}, 0, 1},
+		{[]byte{191}, 191, 1},
+		{[]byte{192, 0}, 192, 2},
+		{[]byte{226, 0x68}, 9000, 2},
+		{[]byte{254, 255}, 16319, 2},
+		{[]byte{255, 0, 1, 0, 0}, 65536, 5},
+	} {
+		length, n, err := subpacketLength(tt.data)
+		if assert.Nil(t, err, tt.data) {
+			assert.Equal(t, tt.length, length, tt.data)
+			assert.Equal(t, tt.n, n, tt.data)
+		}
+	}
+	for _, data := range [][]byte{nil, {192}, {255, 0, 0}} {
+		_, _, err := subpacketLength(data)
+		assert.NotNil(t, err, data)
+	}
+}
+
+func TestParseLargeSubpacket(t *testing.T) {
+	// A 9000-byte notation, whose two-octet length starts with an octet that would be a partial length in a packet
+	// header, followed by a creation time.
+	area := []byte{226, 0x68, 20}
+	area = append(area, bytes.Repeat([]byte{'x'}, 8999)...)
+	area = append(area, 5, subpacketCreationTime, 0x65, 0x92, 0x00, 0x80)
+
+	s := &signature{}
+	if assert.Nil(t, s.parseSubpackets(area, true)) {
+		assert.Equal(t, time.Unix(0x65920080, 0), s.created)
+		assert.False(t, s.unknownCritical)
+	}
+}
@@ -0,0 +1,269 @@
+package debpgp
+
+import (
+	"bytes"
+	"crypto"
+	"fmt"
+	"hash"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Policy controls which signatures are acceptable.  The zero value is a reasonable default.
+type Policy struct {
+	// MinRSABits is the smallest RSA key size that is accepted; if zero, 2048.
+	MinRSABits int
+	// AllowSHA1 accepts signatures that use SHA-1.  MD5 is never accepted.
+	AllowSHA1 bool
+	// AllowExpired accepts signatures made by keys that have expired since, as long as they had not expired when the
+	// signature was made.
+	AllowExpired bool
+	// Now returns the current time, against which key and signature expiration is checked; if nil, time.Now.
+	Now func() time.Time
+}
+
+func (p Policy) now() time.Time {
+	if p.Now == nil {
+		return time.Now()
+	}
+	return p.Now()
+}
+
+func (p Policy) minRSABits() int {
+	if p.MinRSABits == 0 {
+		return 2048
+	}
+	return p.MinRSABits
+}
+
+// checkHash rejects weak hash algorithms.
+func (p Policy) checkHash(h crypto.Hash) error {
+	switch {
+	case h == crypto.MD5:
+		return errors.New("signature uses MD5")
+	case h == crypto.SHA1 && !p.AllowSHA1:
+		return errors.New("signature uses SHA-1")
+	}
+	return nil
+}
+
+// checkKey rejects keys that are revoked, expired, unable to sign or too weak.  The primary key of a subkey is checked
+// as well.
+func (p Policy) checkKey(e *Entity, k *PublicKey, sigCreated time.Time) error {
+	for _, key := range []*PublicKey{e.Primary, k} {
+		if key.Revoked {
+			return errors.Errorf("key %v has been revoked", key.Fingerprint)
+		}
+		if !key.Expires.IsZero() {
+			if !key.Expires.After(sigCreated) {
+				return errors.Errorf("signature was made after key %v expired", key.Fingerprint)
+			}
+			if !p.AllowExpired && !key.Expires.After(p.now()) {
+				return errors.Errorf("key %v expired at %v", key.Fingerprint, key.Expires.UTC().Format(time.RFC3339))
+			}
+		}
+	}
+	switch {
+	case !k.CanSign:
+		return errors.Errorf("key %v is not a signing key", k.Fingerprint)
+	case sigCreated.Before(k.Created):
+		return errors.Errorf("signature predates key %v", k.Fingerprint)
+	case (k.Algorithm == algoRSA || k.Algorithm == algoRSASignOnly) && k.Bits < p.minRSABits():
+		return errors.Errorf("key %v is a %d-bit RSA key; at least %d bits are required", k.Fingerprint, k.Bits,
+			p.minRSABits())
+	}
+	return nil
+}
+
+// Signature describes a signature that has been verified.
+type Signature struct {
+	// Fingerprint identifies the key that made the signature, which may be a subkey.
+	Fingerprint string
+	// PrimaryFingerprint identifies the primary key of the key that made the signature; it is the same as Fingerprint
+	// unless a subkey made the signature.
+	PrimaryFingerprint string
+	UserIDs            []string
+	Created            time.Time
+	Hash               crypto.Hash
+}
+
+// candidate is a key that might have made a signature, along with the entity that it belongs to.
+type candidate struct {
+	e *Entity
+	k *PublicKey
+}
+
+// candidates returns the keys that might have made s, based on its issuer subpackets.  If s names no issuer, every
+// key is a candidate.
+func (kr *Keyring) candidates(s *signature) []candidate {
+	var out []candidate
+	for _, e := range kr.Entities {
+		for _, k := range append([]*PublicKey{e.Primary}, e.Subkeys...) {
+			switch {
+			case s.issuerFpr != nil:
+				if !strings.EqualFold(fmt.Sprintf("%X", s.issuerFpr), k.Fingerprint) {
+					continue
+				}
+			case s.issuerKeyID != 0:
+				if s.issuerKeyID != k.KeyID {
+					continue
+				}
+			}
+			out = append(out, candidate{e, k})
+		}
+	}
+	return out
+}
+
+// verify checks the signatures in sigData (binary or armored) over the data that write produces, returning the first
+// signature that is good and acceptable under p.  If hashes is not nil, signatures must use one of the hash algorithms
+// that it names.
+func (kr *Keyring) verify(sigData []byte, write func(h hash.Hash, sigType byte), hashes []string,
+	p Policy) (*Signature, error) {
+	sigData, err := dearmor(sigData, "PGP SIGNATURE")
+	if err != nil {
+		return nil, err
+	}
+	packets, err := readPackets(sigData)
+	if err != nil {
+		return nil, err
+	}
+
+	var reasons []string
+	for _, pkt := range packets {
+		if pkt.tag != tagSignature {
+			continue
+		}
+		s, err := parseSignature(pkt.body)
+		if err != nil {
+			reasons = append(reasons, err.Error())
+			continue
+		}
+		if hashes != nil && !containsString(hashes, hashNames[s.hash]) {
+			reasons = append(reasons, fmt.Sprintf("signature uses %v, which the Hash armor header does not list", s.hash))
+			continue
+		}
+		if sig, err := kr.verifySignature(s, write, p); err != nil {
+			reasons = append(reasons, err.Error())
+		} else {
+			return sig, nil
+		}
+	}
+	if len(reasons) == 0 {
+		return nil, errors.New("no signatures found")
+	}
+	return nil, errors.Errorf("no good signature: %v", strings.Join(reasons, "; "))
+}
+
+func (kr *Keyring) verifySignature(s *signature, write func(h hash.Hash, sigType byte), p Policy) (*Signature, error) {
+	if s.sigType != sigBinary && s.sigType != sigText {
+		return nil, errors.Errorf("unexpected signature type %#02x", s.sigType)
+	}
+	if err := p.checkHash(s.hash); err != nil {
+		return nil, err
+	}
+	if s.expires != 0 && !s.created.Add(s.expires).After(p.now()) {
+		return nil, errors.New("signature has expired")
+	}
+	if s.created.After(p.now()) {
+		return nil, errors.New("signature was made in the future")
+	}
+
+	candidates := kr.candidates(s)
+	if len(candidates) == 0 {
+		issuer := fmt.Sprintf("%X", s.issuerFpr)
+		if s.issuerFpr == nil {
+			issuer = fmt.Sprintf("%016X", s.issuerKeyID)
+		}
+		return nil, errors.Errorf("signature was made by key %v, which is not in the keyring", issuer)
+	}
+
+	var lastErr error
+	for _, c := range candidates {
+		e, k := c.e, c.k
+		if lastErr = p.checkKey(e, k, s.created); lastErr != nil {
+			continue
+		}
+		h, err := s.newHash()
+		if err != nil {
+			return nil, err
+		}
+		write(h, s.sigType)
+		if lastErr = s.verify(k, h); lastErr != nil {
+			lastErr = errors.Wrapf(lastErr, "signature by key %v", k.Fingerprint)
+			continue
+		}
+		return &Signature{
+			Fingerprint:        k.Fingerprint,
+			PrimaryFingerprint: e.Primary.Fingerprint,
+			UserIDs:            e.UserIDs,
+			Created:            s.created,
+			Hash:               s.hash,
+		}, nil
+	}
+	return nil, lastErr
+}
+
+// VerifyDetached verifies a detached signature (binary or armored, as in a Release.gpg file) over data.  If the
+// signature block holds several signatures, one good signature by a key in the keyring is enough.
+func (kr *Keyring) VerifyDetached(data, sig []byte, p Policy) (*Signature, error) {
+	return kr.verify(sig, func(h hash.Hash, sigType byte) {
+		if sigType == sigText {
+			h.Write(canonicalText(data))
+		} else {
+			h.Write(data)
+		}
+	}, nil, p)
+}
+
+// VerifyClearsigned verifies a cleartext signed message (such as an InRelease file) and returns its text, as decoded by
+// DecodeClearsigned.  The text is only returned if verification succeeds.  The signature's hash algorithm must be
+// listed in the message's "Hash" armor header; if there is none, MD5 is implied, and that is never accepted.
+//
+// Ref.: RFC 4880, section 7
+func (kr *Keyring) VerifyClearsigned(msg []byte, p Policy) ([]byte, *Signature, error) {
+	m, err := DecodeClearsigned(msg)
+	if err != nil {
+		return nil, nil, err
+	}
+	hashes := m.Hashes
+	if len(hashes) == 0 {
+		hashes = []string{hashNames[crypto.MD5]}
+	}
+	sig, err := kr.verify(m.Signature, func(h hash.Hash, sigType byte) {
+		h.Write(m.signedText())
+	}, hashes, p)
+	if err != nil {
+		return nil, nil, err
+	}
+	return m.Text, sig, nil
+}
+
+// signedText returns the text that the signature of a cleartext signed message covers: trailing whitespace is removed
+// from each line, lines end with "\r\n", and the final line break is omitted.
+//
+// Ref.: RFC 4880, section 7.1
+func (m *ClearsignedMessage) signedText() []byte {
+	lines := strings.Split(strings.TrimSuffix(string(m.Text), "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	return []byte(strings.Join(lines, "\r\n"))
+}
+
+// canonicalText converts line endings to "\r\n", as required for text signatures.
+func canonicalText(data []byte) []byte {
+	data = bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+	return bytes.ReplaceAll(data, []byte("\n"), []byte("\r\n"))
+}
+
+func containsString(ss []string, s string) bool {
+	for _, x := range ss {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,290 @@
+package debrelation
+
+import (
+	"strings"
+
+	"github.com/kelleyk/godebian/debversion"
+	"github.com/pkg/errors"
+)
+
+// Ref.: https://www.debian.org/doc/debian-policy/ch-relationships.html
+//       https://wiki.debian.org/BuildProfileSpec
+
+// RestrictionTerm is one element of an architecture restriction list or of a build profile restriction, e.g. the
+// "!i386" in "[amd64 !i386]".
+type RestrictionTerm struct {
+	Negated bool
+	Name    string
+}
+
+func (t RestrictionTerm) String() string {
+	if t.Negated {
+		return "!" + t.Name
+	}
+	return t.Name
+}
+
+// ArchRestrictions is an architecture restriction list such as "[linux-any !armel]".  Its terms are either all
+// negated or all non-negated.
+type ArchRestrictions []RestrictionTerm
+
+func (a ArchRestrictions) String() string {
+	return "[" + joinTerms(a) + "]"
+}
+
+// ProfileRestriction is a single build profile restriction such as "<!nocheck stage1>"; it is satisfied when all of its
+// terms are.
+type ProfileRestriction []RestrictionTerm
+
+func (p ProfileRestriction) String() string {
+	return "<" + joinTerms(p) + ">"
+}
+
+// ProfileFormula is a list of build profile restrictions, e.g. "<!nocheck> <stage1>"; it is satisfied when any one of
+// them is.
+type ProfileFormula []ProfileRestriction
+
+func (f ProfileFormula) String() string {
+	parts := make([]string, len(f))
+	for i, p := range f {
+		parts[i] = p.String()
+	}
+	return strings.Join(parts, " ")
+}
+
+func joinTerms(ts []RestrictionTerm) string {
+	parts := make([]string, len(ts))
+	for i, t := range ts {
+		parts[i] = t.String()
+	}
+	return strings.Join(parts, " ")
+}
+
+// Relation is a single package relation, such as "libc6:any (>= 2.34) [linux-any] <!nocheck>".
+type Relation struct {
+	Name string
+	// ArchQualifier is the text after the ':' in the package name ("any", "native" or an architecture name), or the
+	// empty string if there is none.
+	ArchQualifier string
+	// Constraint is the version restriction, such as ">= 2.34", or nil if there is none.
+	Constraint *debversion.Constraint
+	// Architectures and Profiles are only permitted in source package relationship fields (such as Build-Depends).
+	Architectures ArchRestrictions
+	Profiles      ProfileFormula
+}
+
+// Alternatives is a list of relations separated by '|', any one of which satisfies the group.
+type Alternatives []Relation
+
+// Relations is the parsed contents of a relationship field: a list of comma-separated groups of alternatives, all
+// of which must be satisfied.
+type Relations []Alternatives
+
+// String formats the relation canonically, with single spaces between its parts.  This is not the original text, whose
+// spacing is not recorded, but parsing the result yields an identical Relation.
+func (r Relation) String() string {
+	var b strings.Builder
+	b.WriteString(r.Name)
+	if r.ArchQualifier != "" {
+		b.WriteString(":" + r.ArchQualifier)
+	}
+	if r.Constraint != nil {
+		b.WriteString(" (" + r.Constraint.String() + ")")
+	}
+	if r.Architectures != nil {
+		b.WriteString(" " + r.Architectures.String())
+	}
+	if len(r.Profiles) > 0 {
+		b.WriteString(" " + r.Profiles.String())
+	}
+	return b.String()
+}
+
+func (a Alternatives) String() string {
+	parts := make([]string, len(a))
+	for i, r := range a {
+		parts[i] = r.String()
+	}
+	return strings.Join(parts, " | ")
+}
+
+// String formats the field canonically, on a single line and without a trailing comma.  Like Relation.String, it does
+// not reproduce the original text; parsing the result yields identical Relations, and formatting those again yields the
+// same string.
+func (rs Relations) String() string {
+	parts := make([]string, len(rs))
+	for i, a := range rs {
+		parts[i] = a.String()
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Parse parses the value of a relationship field such as Depends.  An empty (or all-whitespace) value yields an empty
+// list.  A trailing comma is ignored, as it is by dpkg (and as wrap-and-sort -t writes fields), but an empty group
+// anywhere else is an error.
+func Parse(s string) (Relations, error) {
+	var rs Relations
+	if strings.TrimSpace(s) == "" {
+		return rs, nil
+	}
+	groups := strings.Split(s, ",")
+	if len(groups) > 1 && strings.TrimSpace(groups[len(groups)-1]) == "" {
+		groups = groups[:len(groups)-1]
+	}
+	for _, group := range groups {
+		a, err := ParseAlternatives(group)
+		if err != nil {
+			return nil, err
+		}
+		rs = append(rs, a)
+	}
+	return rs, nil
+}
+
+// ParseAlternatives parses a '|'-separated list of relations.
+func ParseAlternatives(s string) (Alternatives, error) {
+	var a Alternatives
+	for _, rel := range strings.Split(s, "|") {
+		r, err := ParseRelation(rel)
+		if err != nil {
+			return nil, err
+		}
+		a = append(a, r)
+	}
+	return a, nil
+}
+
+// ParseRelation parses a single relation, e.g. "libc6:any (>= 2.34) [linux-any] <!nocheck>".
+func ParseRelation(s string) (Relation, error) {
+	r, err := (&relationParser{s: s}).parse()
+	if err != nil {
+		return r, errors.Wrapf(err, "failed to parse relation %q", strings.TrimSpace(s))
+	}
+	return r, nil
+}
+
+type relationParser struct {
+	s   string
+	pos int
+}
+
+func (p *relationParser) skipSpace() {
+	for p.pos < len(p.s) && strings.IndexByte(" \t\r\n", p.s[p.pos]) != -1 {
+		p.pos++
+	}
+}
+
+func (p *relationParser) peek() byte {
+	if p.pos >= len(p.s) {
+		return 0
+	}
+	return p.s[p.pos]
+}
+
+// word consumes a run of characters that may appear in package, architecture and profile names.
+func (p *relationParser) word() string {
+	start := p.pos
+	for p.pos < len(p.s) && strings.IndexByte(" \t\r\n:()[]<>!,|", p.s[p.pos]) == -1 {
+		p.pos++
+	}
+	return p.s[start:p.pos]
+}
+
+// until consumes everything up to (but not including) the delimiter, returning an error if it does not appear.
+func (p *relationParser) until(delim byte) (string, error) {
+	i := strings.IndexByte(p.s[p.pos:], delim)
+	if i == -1 {
+		return "", errors.Errorf("missing %q", delim)
+	}
+	v := p.s[p.pos : p.pos+i]
+	p.pos += i + 1
+	return v, nil
+}
+
+func (p *relationParser) parse() (Relation, error) {
+	var r Relation
+
+	p.skipSpace()
+	if r.Name = p.word(); r.Name == "" {
+		return r, errors.New("missing package name")
+	}
+	if p.peek() == ':' {
+		p.pos++
+		if r.ArchQualifier = p.word(); r.ArchQualifier == "" {
+			return r, errors.New("missing architecture qualifier after ':'")
+		}
+	}
+
+	p.skipSpace()
+	if p.peek() == '(' {
+		p.pos++
+		v, err := p.until(')')
+		if err != nil {
+			return r, err
+		}
+		c, err := debversion.ParseConstraint(v)
+		if err != nil {
+			return r, err
+		}
+		r.Constraint = &c
+		p.skipSpace()
+	}
+
+	if p.peek() == '[' {
+		p.pos++
+		v, err := p.until(']')
+		if err != nil {
+			return r, err
+		}
+		terms, err := parseTerms(v)
+		if err != nil {
+			return r, errors.Wrap(err, "malformed architecture restriction list")
+		}
+		for _, t := range terms[1:] {
+			if t.Negated != terms[0].Negated {
+				return r, errors.New("architecture restriction list mixes negated and non-negated architectures")
+			}
+		}
+		r.Architectures = ArchRestrictions(terms)
+		p.skipSpace()
+	}
+
+	for p.peek() == '<' {
+		p.pos++
+		v, err := p.until('>')
+		if err != nil {
+			return r, err
+		}
+		terms, err := parseTerms(v)
+		if err != nil {
+			return r, errors.Wrap(err, "malformed build profile restriction")
+		}
+		r.Profiles = append(r.Profiles, ProfileRestriction(terms))
+		p.skipSpace()
+	}
+
+	if p.pos != len(p.s) {
+		return r, errors.Errorf("unexpected text: %q", p.s[p.pos:])
+	}
+	return r, nil
+}
+
+// parseTerms parses the whitespace-separated, optionally '!'-prefixed terms inside of a restriction list.
+func parseTerms(s string) ([]RestrictionTerm, error) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return nil, errors.New("empty list")
+	}
+	terms := make([]RestrictionTerm, len(fields))
+	for i, f := range fields {
+		if strings.HasPrefix(f, "!") {
+			terms[i].Negated = true
+			f = f[1:]
+		}
+		if f == "" || strings.ContainsAny(f, "!:()[]<>") {
+			return nil, errors.Errorf("malformed term: %q", fields[i])
+		}
+		terms[i].Name = f
+	}
+	return terms, nil
+}
@@ -0,0 +1,121 @@
+package debrelation
+
+import (
+	"testing"
+
+	"github.com/kelleyk/godebian/debversion"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	rs, err := Parse("libc6 (>= 2.34), foo | bar (<<2.0~),baz")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, Relations{
+		{{Name: "libc6", Constraint: &debversion.Constraint{
+			Op: debversion.OpGreaterEqual, Version: debversion.DebianVersion{UpstreamVersion: "2.34"}}}},
+		{{Name: "foo"}, {Name: "bar", Constraint: &debversion.Constraint{
+			Op: debversion.OpLess, Version: debversion.DebianVersion{UpstreamVersion: "2.0~"}}}},
+		{{Name: "baz"}},
+	}, rs)
+	assert.Equal(t, "libc6 (>= 2.34), foo | bar (<< 2.0~), baz", rs.String())
+
+	rs, err = Parse("  ")
+	assert.NoError(t, err)
+	assert.Len(t, rs, 0)
+
+	// A trailing comma is allowed, as written by wrap-and-sort -t.
+	for s, expected := range map[string]string{
+		"foo,":                              "foo",
+		"foo, \n":                           "foo",
+		"debhelper-compat (= 13),\n foo,\n": "debhelper-compat (= 13), foo",
+	} {
+		rs, err := Parse(s)
+		if assert.NoError(t, err, s) {
+			assert.Equal(t, expected, rs.String(), s)
+		}
+	}
+
+	for _, s := range []string{",", "foo,,bar", ",foo", "foo,,", "foo (>= 1.0", "foo (~ 1.0)", "foo bar", "(>= 1.0)"} {
+		_, err := Parse(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestParseFullGrammar(t *testing.T) {
+	r, err := ParseRelation(" foo:any ( >=1:2.0-1 ) [ amd64  i386 ] <!nocheck> <stage1 cross> ")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, Relation{
+		Name:          "foo",
+		ArchQualifier: "any",
+		Constraint: &debversion.Constraint{Op: debversion.OpGreaterEqual, Version: debversion.DebianVersion{
+			Epoch: "1", UpstreamVersion: "2.0", DebianRevision: "1"}},
+		Architectures: ArchRestrictions{{Name: "amd64"}, {Name: "i386"}},
+		Profiles: ProfileFormula{
+			{{Negated: true, Name: "nocheck"}},
+			{{Name: "stage1"}, {Name: "cross"}},
+		},
+	}, r)
+	assert.Equal(t, "foo:any (>= 1:2.0-1) [amd64 i386] <!nocheck> <stage1 cross>", r.String())
+
+	for _, s := range []string{
+		"foo [amd64 !i386]",
+		"foo <>",
+		"foo []",
+		"foo [amd64",
+		"foo <!nocheck",
+		"foo: (>= 1)",
+		"foo <!nocheck> [amd64]",
+		"foo (>= 1.0) (<< 2.0)",
+		"foo [!!amd64]",
+	} {
+		_, err := ParseRelation(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, s := range []string{
+		"libc6 (>= 2.34), foo | bar:any [amd64] <!nocheck>",
+		"python3:native, libfoo-dev:arm64 (= 1.0-1) [!hurd-any !kfreebsd-any] <!stage1> <cross !nocheck>",
+		"debhelper-compat (= 13), dh-sequence-python3",
+		"a (<< 1), b (<= 1), c (= 1), d (>= 1), e (>> 1)",
+	} {
+		rs, err := Parse(s)
+		if !assert.NoError(t, err, s) {
+			continue
+		}
+		assert.Equal(t, s, rs.String())
+
+		again, err := Parse(rs.String())
+		if assert.NoError(t, err, s) {
+			assert.Equal(t, rs, again)
+		}
+	}
+}
+
+func TestStringCanonicalizes(t *testing.T) {
+	for s, expected := range map[string]string{
+		"libc6(>=2.34)":                               "libc6 (>= 2.34)",
+		" foo |bar:any ( << 1.0 ) ,\n baz ,":          "foo | bar:any (<< 1.0), baz",
+		"foo [ amd64  i386 ]<!nocheck><stage1 cross>": "foo [amd64 i386] <!nocheck> <stage1 cross>",
+		"debhelper-compat (= 13),\n dh-python,\n":     "debhelper-compat (= 13), dh-python",
+		"foo (< 0:1.0-0)":                             "foo (< 0:1.0-0)",
+	} {
+		rs, err := Parse(s)
+		if !assert.NoError(t, err, s) {
+			continue
+		}
+		canonical := rs.String()
+		assert.Equal(t, expected, canonical, s)
+
+		again, err := Parse(canonical)
+		if assert.NoError(t, err, s) {
+			assert.Equal(t, rs, again, s)
+			assert.Equal(t, canonical, again.String(), s)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package debrelation
+
+import (
+	"github.com/kelleyk/godebian/debarch"
+)
+
+// BuildContext describes a package build for the purpose of evaluating source package relationship fields.
+type BuildContext struct {
+	// HostArch is the architecture that the built packages will run on; architecture restriction lists are evaluated
+	// against it.
+	HostArch string
+	// BuildArch is the architecture of the machine performing the build.  It is the same as HostArch except when
+	// cross-building, and determines which packages satisfy relations qualified with ":native".
+	BuildArch string
+	// Profiles lists the active build profiles (e.g. "nocheck", "stage1").
+	Profiles []string
+}
+
+// Matches reports whether arch satisfies the restriction list: a list of architectures (or wildcards) matches if any
+// of them do, and a list of negated architectures matches if none of them do.  An empty list matches everything.
+func (a ArchRestrictions) Matches(arch string) bool {
+	if len(a) == 0 {
+		return true
+	}
+	for _, t := range a {
+		if debarch.Is(arch, t.Name) {
+			return !t.Negated
+		}
+	}
+	return a[0].Negated
+}
+
+// Matches reports whether the restriction's terms are all satisfied by the given set of active profiles.
+func (p ProfileRestriction) Matches(profiles []string) bool {
+	for _, t := range p {
+		active := false
+		for _, name := range profiles {
+			if name == t.Name {
+				active = true
+				break
+			}
+		}
+		if active == t.Negated {
+			return false
+		}
+	}
+	return true
+}
+
+// Matches reports whether any of the formula's restrictions is satisfied by the given set of active profiles.  An
+// empty formula matches everything.
+func (f ProfileFormula) Matches(profiles []string) bool {
+	if len(f) == 0 {
+		return true
+	}
+	for _, p := range f {
+		if p.Matches(profiles) {
+			return true
+		}
+	}
+	return false
+}
+
+// Applies reports whether the relation's architecture and build profile restrictions are satisfied in ctx.
+func (r Relation) Applies(ctx BuildContext) bool {
+	return r.Architectures.Matches(ctx.HostArch) && r.Profiles.Matches(ctx.Profiles)
+}
+
+// Reduce returns the relations that apply in ctx, with their restrictions removed, as dpkg-checkbuilddeps(1) does.
+// Relations that do not apply are dropped from their group of alternatives, and groups left empty are dropped
+// entirely.
+func (rs Relations) Reduce(ctx BuildContext) Relations {
+	reduced := Relations{}
+	for _, alts := range rs {
+		var kept Alternatives
+		for _, r := range alts {
+			if r.Applies(ctx) {
+				r.Architectures, r.Profiles = nil, nil
+				kept = append(kept, r)
+			}
+		}
+		if len(kept) > 0 {
+			reduced = append(reduced, kept)
+		}
+	}
+	return reduced
+}
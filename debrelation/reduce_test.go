@@ -0,0 +1,65 @@
+package debrelation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArchRestrictionsMatches(t *testing.T) {
+	for _, tt := range []struct {
+		list     string
+		arch     string
+		expected bool
+	}{
+		{"foo [amd64 i386]", "amd64", true},
+		{"foo [amd64 i386]", "arm64", false},
+		{"foo [linux-any]", "arm64", true},
+		{"foo [linux-any]", "hurd-i386", false},
+		{"foo [!hurd-any !kfreebsd-any]", "amd64", true},
+		{"foo [!hurd-any !kfreebsd-any]", "hurd-i386", false},
+		{"foo [any-amd64]", "x32", true},
+		{"foo", "anything", true},
+	} {
+		r, err := ParseRelation(tt.list)
+		if assert.NoError(t, err) {
+			assert.Equal(t, tt.expected, r.Architectures.Matches(tt.arch), "%q on %v", tt.list, tt.arch)
+		}
+	}
+}
+
+func TestProfileFormulaMatches(t *testing.T) {
+	for _, tt := range []struct {
+		formula  string
+		profiles []string
+		expected bool
+	}{
+		{"foo <!nocheck>", nil, true},
+		{"foo <!nocheck>", []string{"nocheck"}, false},
+		{"foo <stage1>", nil, false},
+		{"foo <stage1 cross>", []string{"stage1"}, false},
+		{"foo <stage1 cross>", []string{"stage1", "cross"}, true},
+		{"foo <stage1> <cross>", []string{"cross"}, true},
+		{"foo <!stage1 !nocheck> <cross>", []string{"nocheck"}, false},
+		{"foo", []string{"nocheck"}, true},
+	} {
+		r, err := ParseRelation(tt.formula)
+		if assert.NoError(t, err) {
+			assert.Equal(t, tt.expected, r.Profiles.Matches(tt.profiles), "%q with %v", tt.formula, tt.profiles)
+		}
+	}
+}
+
+func TestReduce(t *testing.T) {
+	rs, err := Parse("debhelper-compat (= 13), libfoo-dev [linux-any] | libfoo-hurd-dev [hurd-any], " +
+		"python3:any <!nodoc>, check <!nocheck>, libbar-dev:native [!amd64]")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, "debhelper-compat (= 13), libfoo-dev, python3:any, check",
+		rs.Reduce(BuildContext{HostArch: "amd64", BuildArch: "amd64"}).String())
+	assert.Equal(t, "debhelper-compat (= 13), libfoo-hurd-dev, libbar-dev:native",
+		rs.Reduce(BuildContext{HostArch: "hurd-i386", Profiles: []string{"nocheck", "nodoc"}}).String())
+	assert.Equal(t, Relations{}, Relations{}.Reduce(BuildContext{HostArch: "amd64"}))
+}
@@ -0,0 +1,407 @@
+package debrepo
+
+import (
+	"archive/tar"
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kelleyk/godebian/debcontrol"
+	"github.com/kelleyk/godebian/debfile"
+	"github.com/kelleyk/godebian/debpgp"
+	"github.com/kelleyk/godebian/debversion"
+	"github.com/pkg/errors"
+)
+
+// GenerateOptions configures Generate.
+type GenerateOptions struct {
+	// Suite names the directory under "dists" that the indices are written to, and is the Suite field of the Release
+	// file.  It is required.
+	Suite       string
+	Codename    string
+	Origin      string
+	Label       string
+	Version     string
+	Description string
+
+	// Architectures lists the architectures to write indices for.  If it is empty, the architectures of the packages
+	// found (other than "all") are used.  Packages for architecture "all" are listed in the indices of every
+	// architecture.
+	Architectures []string
+
+	// ValidFor, if nonzero, sets the Valid-Until field to this long after the Date field.
+	ValidFor time.Duration
+	// ByHash also writes each index file into "by-hash" directories, so that clients can fetch a consistent set of
+	// indices while the repository is being updated.  Files left there by earlier runs are not removed.
+	ByHash bool
+	// SigningKey, if not nil, signs the Release file, which is then also written as InRelease and Release.gpg.
+	SigningKey *debpgp.SigningKey
+	// Now returns the time for the Date field and the signatures; if nil, time.Now.
+	Now func() time.Time
+}
+
+// poolPackage is a .deb file found in the pool.
+type poolPackage struct {
+	Component string
+	*debcontrol.BinaryPackage
+	// Stanza is the package's entry in the Packages index.
+	Stanza debcontrol.Paragraph
+	// Contents lists the files that the package installs, without a leading '/'.
+	Contents []string
+	// Location is the package's location in the Contents index, e.g. "utils/hello".
+	Location string
+}
+
+// packagesFields are the fields that Generate adds to each package's control file; they go before Description, as
+// dpkg-scanpackages(1) puts them.
+var packagesFields = []string{"Filename", "Size", "MD5sum", "SHA1", "SHA256", "SHA512"}
+
+// Generate builds the metadata of a repository from the .deb files in the directory root/pool, replacing the work of
+// apt-ftparchive(1).  Each package's component is the name of the subdirectory of "pool" that holds it (as in
+// "pool/main/h/hello/hello_2.10-3_amd64.deb").
+//
+// For the suite in opts, it writes to root/dists/<suite>:
+//
+//   - <component>/binary-<arch>/Packages, compressed as ".gz" and ".xz" as well,
+//   - <component>/Contents-<arch> and Contents-<arch>.gz,
+//   - Release, and, if opts.SigningKey is set, InRelease and Release.gpg.
+//
+// It returns the release that it wrote.
+func Generate(root string, opts GenerateOptions) (*Release, error) {
+	if opts.Suite == "" {
+		return nil, errors.New("no suite given")
+	}
+	pkgs, err := scanPool(root)
+	if err != nil {
+		return nil, err
+	}
+
+	archs := opts.Architectures
+	if len(archs) == 0 {
+		archs = uniqueSorted(pkgs, func(p *poolPackage) string { return p.Architecture })
+		archs = removeString(archs, "all")
+		if len(archs) == 0 {
+			return nil, errors.New("no architecture-specific packages found, and no architectures given")
+		}
+	}
+	for _, p := range pkgs {
+		if p.Architecture != "all" && !containsString(archs, p.Architecture) {
+			return nil, errors.Errorf("%v has architecture %v, which is not one of the suite's architectures",
+				p.Stanza.Value("Filename"), p.Architecture)
+		}
+	}
+	components := uniqueSorted(pkgs, func(p *poolPackage) string { return p.Component })
+
+	now := time.Now
+	if opts.Now != nil {
+		now = opts.Now
+	}
+	rel := &Release{
+		Origin:        opts.Origin,
+		Label:         opts.Label,
+		Suite:         opts.Suite,
+		Codename:      opts.Codename,
+		Version:       opts.Version,
+		Description:   opts.Description,
+		Date:          now().UTC().Truncate(time.Second),
+		Architectures: archs,
+		Components:    components,
+		AcquireByHash: opts.ByHash,
+	}
+	if opts.ValidFor != 0 {
+		rel.ValidUntil = rel.Date.Add(opts.ValidFor)
+	}
+
+	dist := filepath.Join(root, "dists", opts.Suite)
+	for _, component := range components {
+		for _, arch := range archs {
+			var selected []*poolPackage
+			for _, p := range pkgs {
+				if p.Component == component && (p.Architecture == arch || p.Architecture == "all") {
+					selected = append(selected, p)
+				}
+			}
+
+			var packages bytes.Buffer
+			for i, p := range selected {
+				if i > 0 {
+					packages.WriteString("\n")
+				}
+				packages.WriteString(p.Stanza.String())
+			}
+			files, err := writeIndex(dist, path.Join(component, "binary-"+arch, "Packages"), packages.Bytes(),
+				[]string{"", ".gz", ".xz"}, opts.ByHash)
+			if err != nil {
+				return nil, err
+			}
+			rel.Files = append(rel.Files, files...)
+
+			files, err = writeIndex(dist, path.Join(component, "Contents-"+arch), formatContents(selected),
+				[]string{"", ".gz"}, opts.ByHash)
+			if err != nil {
+				return nil, err
+			}
+			rel.Files = append(rel.Files, files...)
+		}
+	}
+	sort.Slice(rel.Files, func(i, j int) bool { return rel.Files[i].Path < rel.Files[j].Path })
+
+	// Release is written before the signatures of it, all from the same bytes.  The signatures are made first so that a
+	// signing error leaves the old files alone.
+	release := []byte(rel.String())
+	var inRelease, sig []byte
+	if opts.SigningKey != nil {
+		var err error
+		if inRelease, err = opts.SigningKey.Clearsign(release, rel.Date); err != nil {
+			return nil, errors.Wrap(err, "failed to sign release file")
+		}
+		if sig, err = opts.SigningKey.SignDetached(release, rel.Date); err != nil {
+			return nil, errors.Wrap(err, "failed to sign release file")
+		}
+	}
+	if err := writeFile(filepath.Join(dist, "Release"), release); err != nil {
+		return nil, err
+	}
+	if opts.SigningKey != nil {
+		if err := writeFile(filepath.Join(dist, "InRelease"), inRelease); err != nil {
+			return nil, err
+		}
+		if err := writeFile(filepath.Join(dist, "Release.gpg"), sig); err != nil {
+			return nil, err
+		}
+	}
+	return rel, nil
+}
+
+// scanPool loads every .deb file under root/pool, sorted by component, name, version and architecture.
+func scanPool(root string) ([]*poolPackage, error) {
+	var pkgs []*poolPackage
+	err := filepath.WalkDir(filepath.Join(root, "pool"), func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(p, ".deb") {
+			return err
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		pkg, err := loadPoolPackage(root, filepath.ToSlash(rel))
+		if err != nil {
+			return errors.Wrapf(err, "failed to load %v", p)
+		}
+		pkgs = append(pkgs, pkg)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) == 0 {
+		return nil, errors.Errorf("no packages found in %v", filepath.Join(root, "pool"))
+	}
+
+	sort.Slice(pkgs, func(i, j int) bool {
+		a, b := pkgs[i], pkgs[j]
+		if a.Component != b.Component {
+			return a.Component < b.Component
+		}
+		if a.Package != b.Package {
+			return a.Package < b.Package
+		}
+		if c := debversion.Compare(a.Version, b.Version); c != 0 {
+			return c < 0
+		}
+		return a.Architecture < b.Architecture
+	})
+	for i := 1; i < len(pkgs); i++ {
+		a, b := pkgs[i-1], pkgs[i]
+		if a.Component == b.Component && a.String() == b.String() {
+			return nil, errors.Errorf("%v and %v are both %v", a.Stanza.Value("Filename"), b.Stanza.Value("Filename"), a)
+		}
+	}
+	return pkgs, nil
+}
+
+// loadPoolPackage loads the .deb file at filename, which is relative to root and uses forward slashes.
+func loadPoolPackage(root, filename string) (*poolPackage, error) {
+	parts := strings.Split(filename, "/")
+	if len(parts) < 3 {
+		return nil, errors.New("package is not in a component directory")
+	}
+
+	data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(filename)))
+	if err != nil {
+		return nil, err
+	}
+	d, err := debfile.Load(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	e, ok := d.Control().Get("control")
+	if !ok {
+		return nil, errors.New("package has no control file")
+	}
+	stanza, err := debcontrol.NewReader(bytes.NewReader(e.Data)).Next()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse control file")
+	}
+	bp, err := debcontrol.ParseBinaryPackage(stanza)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := stanza.Get("Installed-Size"); !ok {
+		stanza.Set("Installed-Size", strconv.FormatInt(d.Data().InstalledSize(), 10))
+	}
+	cs, err := debfile.ComputeChecksums(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	values := []string{filename, strconv.FormatInt(cs.Size, 10), cs.MD5, cs.SHA1, cs.SHA256, cs.SHA512}
+	var fields []debcontrol.Field
+	for i, name := range packagesFields {
+		stanza.Delete(name)
+		fields = append(fields, debcontrol.Field{Name: name, Value: values[i]})
+	}
+	i := len(stanza.Fields)
+	for j, f := range stanza.Fields {
+		if strings.EqualFold(f.Name, "Description") {
+			i = j
+		}
+	}
+	stanza.Fields = append(stanza.Fields[:i], append(fields, stanza.Fields[i:]...)...)
+
+	section := stanza.Value("Section")
+	if section == "" {
+		section = "unknown"
+	}
+	pkg := &poolPackage{
+		Component:     parts[1],
+		BinaryPackage: bp,
+		Stanza:        stanza,
+		Location:      section + "/" + bp.Package,
+	}
+	for name, e := range d.Data().Contents {
+		if e.Header.Typeflag != tar.TypeDir {
+			pkg.Contents = append(pkg.Contents, strings.TrimPrefix(name, "/"))
+		}
+	}
+	return pkg, nil
+}
+
+// formatContents formats a Contents index, which maps each file to the packages that contain it.
+//
+// Ref.: https://wiki.debian.org/DebianRepository/Format#A.22Contents.22_indices
+func formatContents(pkgs []*poolPackage) []byte {
+	locations := make(map[string][]string)
+	for _, p := range pkgs {
+		for _, name := range p.Contents {
+			locations[name] = append(locations[name], p.Location)
+		}
+	}
+	names := make([]string, 0, len(locations))
+	for name := range locations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b bytes.Buffer
+	for _, name := range names {
+		ls := locations[name]
+		sort.Strings(ls)
+		fmt.Fprintf(&b, "%-55s %s\n", name, strings.Join(ls, ","))
+	}
+	return b.Bytes()
+}
+
+// writeIndex writes an index file, at a path relative to dist, in each of the given compressions (with "" meaning
+// uncompressed), returning the entries for the Release file.
+func writeIndex(dist, name string, data []byte, exts []string, byHash bool) ([]ReleaseFile, error) {
+	var files []ReleaseFile
+	for _, ext := range exts {
+		var b bytes.Buffer
+		w, err := debfile.Compress(&b, ext)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(data); err != nil {
+			w.Close()
+			return nil, errors.Wrapf(err, "failed to compress %v%v", name, ext)
+		}
+		if err := w.Close(); err != nil {
+			return nil, errors.Wrapf(err, "failed to compress %v%v", name, ext)
+		}
+
+		f := ReleaseFile{Path: name + ext}
+		if f.Checksums, err = debfile.ComputeChecksums(bytes.NewReader(b.Bytes())); err != nil {
+			return nil, err
+		}
+		if err := writeFile(filepath.Join(dist, filepath.FromSlash(f.Path)), b.Bytes()); err != nil {
+			return nil, err
+		}
+		if byHash {
+			for hashName, sum := range map[string]string{
+				"MD5Sum": f.MD5, "SHA1": f.SHA1, "SHA256": f.SHA256, "SHA512": f.SHA512,
+			} {
+				p := filepath.Join(dist, filepath.FromSlash(path.Dir(f.Path)), "by-hash", hashName, sum)
+				if err := writeFile(p, b.Bytes()); err != nil {
+					return nil, err
+				}
+			}
+		}
+		files = append(files, f)
+	}
+	return files, nil
+}
+
+// writeFile replaces a file atomically, creating its directory if necessary, so that clients never see a partly
+// written index.
+func writeFile(name string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return err
+	}
+	tmp := name + ".new"
+	err := os.WriteFile(tmp, data, 0644)
+	if err == nil {
+		err = os.Rename(tmp, name)
+	}
+	if err != nil {
+		os.Remove(tmp)
+	}
+	return err
+}
+
+func uniqueSorted(pkgs []*poolPackage, f func(*poolPackage) string) []string {
+	var out []string
+	for _, p := range pkgs {
+		if s := f(p); !containsString(out, s) {
+			out = append(out, s)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func containsString(ss []string, s string) bool {
+	for _, x := range ss {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+func removeString(ss []string, s string) []string {
+	var out []string
+	for _, x := range ss {
+		if x != s {
+			out = append(out, x)
+		}
+	}
+	return out
+}
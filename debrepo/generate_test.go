@@ -0,0 +1,188 @@
+package debrepo
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kelleyk/godebian/debcontrol"
+	"github.com/kelleyk/godebian/debfile"
+	"github.com/kelleyk/godebian/debpgp"
+	"github.com/stretchr/testify/assert"
+)
+
+// copyTestPool copies testdata/pool into a new repository root.
+func copyTestPool(t *testing.T) string {
+	root := t.TempDir()
+	err := filepath.Walk("testdata/pool", func(p string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		dst := filepath.Join(root, strings.TrimPrefix(p, "testdata/"))
+		if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			return err
+		}
+		return os.WriteFile(dst, data, 0644)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+func TestGenerate(t *testing.T) {
+	root := copyTestPool(t)
+	key, err := debpgp.LoadSigningKey("testdata/archive-key-secret.asc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	date := time.Date(2026, 11, 2, 9, 46, 46, 0, time.UTC)
+	policy := debpgp.Policy{Now: func() time.Time { return date.Add(time.Hour) }}
+
+	rel, err := Generate(root, GenerateOptions{
+		Suite:      "stable",
+		Codename:   "internal",
+		Origin:     "Example",
+		ValidFor:   7 * 24 * time.Hour,
+		ByHash:     true,
+		SigningKey: key,
+		Now:        func() time.Time { return date },
+	})
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, []string{"amd64", "arm64"}, rel.Architectures)
+	assert.Equal(t, []string{"main", "non-free"}, rel.Components)
+	assert.Equal(t, date, rel.Date)
+	assert.Equal(t, date.Add(7*24*time.Hour), rel.ValidUntil)
+
+	dist := filepath.Join(root, "dists", "stable")
+	kr, err := debpgp.LoadKeyring("testdata/archive-key.asc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(filepath.Join(dist, "InRelease"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed, signature, err := VerifyInRelease(f, kr, policy)
+	f.Close()
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.True(t, date.Equal(signature.Created))
+	rel.Paragraph, signed.Paragraph = debcontrol.Paragraph{}, debcontrol.Paragraph{}
+	assert.Equal(t, rel, signed)
+
+	f, err = os.Open(filepath.Join(dist, "Release"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := os.Open(filepath.Join(dist, "Release.gpg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = VerifyRelease(f, sig, kr, policy)
+	f.Close()
+	sig.Close()
+	assert.Nil(t, err)
+
+	var paths []string
+	for _, rf := range rel.Files {
+		paths = append(paths, rf.Path)
+		for _, p := range []string{rf.Path, rf.ByHashPath()} {
+			f, err := os.Open(filepath.Join(dist, filepath.FromSlash(p)))
+			if !assert.Nil(t, err, p) {
+				continue
+			}
+			assert.Nil(t, rel.VerifyFile(rf.Path, f), p)
+			f.Close()
+		}
+	}
+	assert.Contains(t, paths, "main/binary-arm64/Packages.xz")
+	assert.Contains(t, paths, "non-free/Contents-amd64.gz")
+
+	r, err := OpenPackages(filepath.Join(dist, "main", "binary-arm64", "Packages.gz"))
+	if !assert.Nil(t, err) {
+		return
+	}
+	pkgs := readAllPackages(t, r)
+	r.Close()
+	if assert.Len(t, pkgs, 2) {
+		assert.Equal(t, "hello_2.10-3_arm64", pkgs[0].String())
+		assert.Equal(t, "pool/main/h/hello/hello_2.10-3_arm64.deb", pkgs[0].Filename)
+		assert.Equal(t, "test package hello", pkgs[0].Description)
+		assert.Equal(t, "test package hello\nLong description.", pkgs[0].Paragraph.Value("Description"))
+		assert.Equal(t, "hello-doc_2.10-3_all", pkgs[1].String())
+		assert.Equal(t, "doc", pkgs[1].Section)
+		// The control file has no Installed-Size field, so it is computed: two 1 KiB files and six directories.
+		assert.Equal(t, int64(8), pkgs[1].InstalledSize)
+
+		f, err := os.Open(filepath.Join(root, filepath.FromSlash(pkgs[0].Filename)))
+		if assert.Nil(t, err) {
+			cs, err := debfile.ComputeChecksums(f)
+			f.Close()
+			assert.Nil(t, err)
+			assert.Equal(t, cs.Size, pkgs[0].Size)
+			assert.Equal(t, cs.SHA256, pkgs[0].SHA256)
+		}
+	}
+
+	contents, err := os.ReadFile(filepath.Join(dist, "main", "Contents-amd64"))
+	if assert.Nil(t, err) {
+		lines := strings.Split(strings.TrimSuffix(string(contents), "\n"), "\n")
+		if assert.Len(t, lines, 3) {
+			assert.Equal(t, []string{"usr/bin/hello", "utils/hello"}, strings.Fields(lines[0]))
+			assert.Equal(t, []string{"usr/share/doc/hello-doc/manual.txt", "doc/hello-doc"}, strings.Fields(lines[1]))
+			assert.Equal(t, []string{"usr/share/doc/hello/copyright", "doc/hello-doc,utils/hello"},
+				strings.Fields(lines[2]))
+		}
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	root := copyTestPool(t)
+
+	_, err := Generate(root, GenerateOptions{})
+	assert.NotNil(t, err)
+	_, err = Generate(root, GenerateOptions{Suite: "stable", Architectures: []string{"amd64"}})
+	assert.NotNil(t, err)
+	_, err = Generate(t.TempDir(), GenerateOptions{Suite: "stable"})
+	assert.NotNil(t, err)
+
+	data, err := os.ReadFile("testdata/pool/main/h/hello/hello_2.10-3_amd64.deb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "pool", "main", "hello_2.10-3_amd64.deb"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err = Generate(root, GenerateOptions{Suite: "stable"})
+	assert.NotNil(t, err)
+	_, err = os.Stat(filepath.Join(root, "dists"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "a", "Release")
+	if assert.Nil(t, writeFile(name, []byte("x"))) {
+		data, _ := os.ReadFile(name)
+		assert.Equal(t, "x", string(data))
+	}
+
+	// Renaming over a non-empty directory fails, and must not leave the temporary file behind.
+	name = filepath.Join(dir, "InRelease")
+	if err := os.MkdirAll(filepath.Join(name, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	assert.NotNil(t, writeFile(name, []byte("x")))
+	_, err := os.Stat(name + ".new")
+	assert.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,134 @@
+// Package debrepo reads and writes the metadata of APT repositories: Packages, Sources and Release files.
+package debrepo
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/kelleyk/godebian/debcontrol"
+	"github.com/kelleyk/godebian/debfile"
+	"github.com/kelleyk/godebian/debversion"
+	"github.com/pkg/errors"
+)
+
+// compressionExts are the extensions that an index file may have, in the order that APT prefers them.
+var compressionExts = []string{".xz", ".gz", ".bz2", ".lz", ".zst"}
+
+// indexFile is an open (and possibly compressed) index file.
+type indexFile struct {
+	r       *debcontrol.Reader
+	closers []io.Closer
+}
+
+// openIndex opens an index file, decompressing it according to its extension.
+func openIndex(path string) (*indexFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	ext := filepath.Ext(path)
+	isCompressed := false
+	for _, e := range compressionExts {
+		isCompressed = isCompressed || ext == e
+	}
+	if !isCompressed {
+		ext = ""
+	}
+
+	r, err := debfile.Decompress(f, ext)
+	if err != nil {
+		f.Close()
+		return nil, errors.Wrapf(err, "failed to open %v", path)
+	}
+	idx := &indexFile{r: debcontrol.NewReader(r)}
+	if c, ok := r.(io.Closer); ok && ext != "" {
+		idx.closers = append(idx.closers, c)
+	}
+	idx.closers = append(idx.closers, f)
+	return idx, nil
+}
+
+func (idx *indexFile) Close() error {
+	var firstErr error
+	for _, c := range idx.closers {
+		if err := c.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// parseSize parses a nonnegative integer field such as Size or Installed-Size.  An absent field is zero.
+func parseSize(p debcontrol.Paragraph, name string) (int64, error) {
+	s, ok := p.Get(name)
+	if !ok {
+		return 0, nil
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n < 0 {
+		return 0, errors.Errorf("malformed %v field: %q", name, s)
+	}
+	return n, nil
+}
+
+// parseSourceField parses the Source field of a binary package, which names the source package and, if its version
+// differs from that of the binary package, also gives the source version in parentheses (e.g. "glibc (2.36-9)").
+func parseSourceField(value string, binaryVersion debversion.DebianVersion) (string, debversion.DebianVersion, error) {
+	name, rest, ok := strings.Cut(strings.TrimSpace(value), " ")
+	if !ok {
+		return name, binaryVersion, nil
+	}
+	rest = strings.TrimSpace(rest)
+	if !strings.HasPrefix(rest, "(") || !strings.HasSuffix(rest, ")") {
+		return "", debversion.DebianVersion{}, errors.Errorf("malformed Source field: %q", value)
+	}
+	v, err := debversion.FromString(rest[1 : len(rest)-1])
+	if err != nil {
+		return "", debversion.DebianVersion{}, errors.Wrapf(err, "malformed Source field: %q", value)
+	}
+	return name, v, nil
+}
+
+// checksumLine is a line of a checksum table field, such as Files or Checksums-Sha256 in a Sources index or SHA256 in
+// a Release file.
+type checksumLine struct {
+	Hash string
+	Size int64
+	Name string
+}
+
+// parseChecksumTable parses the value of a checksum table field, in which each line has the form "<hash> <size>
+// <name>".  The first line of the value, which is empty for such fields, is ignored.
+func parseChecksumTable(name, value string) ([]checksumLine, error) {
+	var lines []checksumLine
+	for _, line := range strings.Split(value, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 3 {
+			return nil, errors.Errorf("malformed line in %v field: %q", name, line)
+		}
+		size, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil || size < 0 {
+			return nil, errors.Errorf("malformed size in %v field: %q", name, line)
+		}
+		lines = append(lines, checksumLine{Hash: fields[0], Size: size, Name: fields[2]})
+	}
+	return lines, nil
+}
+
+// splitList splits a comma-separated field such as Binary into its trimmed, nonempty elements.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,115 @@
+package debrepo
+
+import (
+	"io"
+
+	"github.com/kelleyk/godebian/debcontrol"
+	"github.com/kelleyk/godebian/debversion"
+	"github.com/pkg/errors"
+)
+
+// BinaryPackage is a stanza from a Packages index.
+type BinaryPackage struct {
+	*debcontrol.BinaryPackage
+
+	// Source and SourceVersion identify the source package that the binary package was built from.  When the stanza has
+	// no Source field, they are the name and version of the binary package itself.
+	Source        string
+	SourceVersion debversion.DebianVersion
+
+	Section  string
+	Priority string
+	// InstalledSize is the value of the Installed-Size field, in KiB.
+	InstalledSize int64
+	Maintainer    string
+	// Description is the synopsis of the package.  The long description is usually in a separate Translation index,
+	// identified by DescriptionMD5, but if the index includes it, it is in the Description field of Paragraph.
+	Description    string
+	DescriptionMD5 string
+
+	// Filename is the path of the .deb file, relative to the root of the repository.
+	Filename string
+	Size     int64
+	MD5sum   string
+	SHA1     string
+	SHA256   string
+	SHA512   string
+}
+
+// ParsePackagesStanza builds a BinaryPackage from a stanza of a Packages index.  In addition to the fields required by
+// debcontrol.ParseBinaryPackage, the Filename and Size fields are required.
+func ParsePackagesStanza(p debcontrol.Paragraph) (*BinaryPackage, error) {
+	bp, err := debcontrol.ParseBinaryPackage(p)
+	if err != nil {
+		return nil, err
+	}
+	synopsis, _ := debcontrol.SplitDescription(p.Value("Description"))
+	b := &BinaryPackage{
+		BinaryPackage:  bp,
+		Section:        p.Value("Section"),
+		Priority:       p.Value("Priority"),
+		Maintainer:     p.Value("Maintainer"),
+		Description:    synopsis,
+		DescriptionMD5: p.Value("Description-md5"),
+		Filename:       p.Value("Filename"),
+		MD5sum:         p.Value("MD5sum"),
+		SHA1:           p.Value("SHA1"),
+		SHA256:         p.Value("SHA256"),
+		SHA512:         p.Value("SHA512"),
+	}
+	for _, name := range []string{"Filename", "Size"} {
+		if _, ok := p.Get(name); !ok {
+			return nil, errors.Errorf("package %v is missing the %v field", bp, name)
+		}
+	}
+
+	if b.Size, err = parseSize(p, "Size"); err != nil {
+		return nil, errors.Wrapf(err, "package %v", bp)
+	}
+	if b.InstalledSize, err = parseSize(p, "Installed-Size"); err != nil {
+		return nil, errors.Wrapf(err, "package %v", bp)
+	}
+
+	b.Source, b.SourceVersion = bp.Package, bp.Version
+	if source, ok := p.Get("Source"); ok {
+		if b.Source, b.SourceVersion, err = parseSourceField(source, bp.Version); err != nil {
+			return nil, errors.Wrapf(err, "package %v", bp)
+		}
+	}
+
+	return b, nil
+}
+
+// PackagesReader reads the stanzas of a Packages index one at a time, so that even very large indices need not be held
+// in memory.
+type PackagesReader struct {
+	idx *indexFile
+}
+
+// NewPackagesReader returns a reader for an uncompressed Packages index.
+func NewPackagesReader(r io.Reader) *PackagesReader {
+	return &PackagesReader{idx: &indexFile{r: debcontrol.NewReader(r)}}
+}
+
+// OpenPackages opens a Packages index file, which is decompressed according to its extension (e.g. "Packages.xz").
+func OpenPackages(path string) (*PackagesReader, error) {
+	idx, err := openIndex(path)
+	if err != nil {
+		return nil, err
+	}
+	return &PackagesReader{idx: idx}, nil
+}
+
+// Next returns the next package in the index.  It returns io.EOF when there are no more packages.
+func (r *PackagesReader) Next() (*BinaryPackage, error) {
+	p, err := r.idx.r.Next()
+	if err != nil {
+		return nil, err
+	}
+	return ParsePackagesStanza(p)
+}
+
+// Close releases the resources held by a reader returned by OpenPackages.
+func (r *PackagesReader) Close() error {
+	return r.idx.Close()
+}
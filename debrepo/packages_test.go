@@ -0,0 +1,103 @@
+package debrepo
+
+import (
+	"io"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func readAllPackages(t *testing.T, r *PackagesReader) []*BinaryPackage {
+	var pkgs []*BinaryPackage
+	for {
+		p, err := r.Next()
+		if err == io.EOF {
+			return pkgs
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		pkgs = append(pkgs, p)
+	}
+}
+
+func TestOpenPackages(t *testing.T) {
+	for _, name := range []string{"Packages", "Packages.gz", "Packages.xz", "Packages.zst"} {
+		if strings.HasSuffix(name, ".zst") {
+			if _, err := exec.LookPath("zstd"); err != nil {
+				t.Logf("skipping %v: %v", name, err)
+				continue
+			}
+		}
+
+		r, err := OpenPackages("testdata/" + name)
+		if !assert.Nil(t, err, name) {
+			continue
+		}
+		pkgs := readAllPackages(t, r)
+		assert.Nil(t, r.Close())
+
+		if !assert.Equal(t, 3, len(pkgs), name) {
+			continue
+		}
+		assert.Equal(t, "hello_2.10-3_amd64", pkgs[0].String())
+		assert.Equal(t, "libc6_2.36-9+deb12u4_amd64", pkgs[1].String())
+		assert.Equal(t, "tzdata_2024a-0+deb12u1_all", pkgs[2].String())
+	}
+}
+
+func TestParsePackagesStanza(t *testing.T) {
+	r, err := OpenPackages("testdata/Packages")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	pkgs := readAllPackages(t, r)
+
+	hello := pkgs[0]
+	assert.Equal(t, "hello", hello.Source)
+	assert.Equal(t, "2.10-3", hello.SourceVersion.String())
+	assert.Equal(t, "devel", hello.Section)
+	assert.Equal(t, "optional", hello.Priority)
+	assert.Equal(t, int64(280), hello.InstalledSize)
+	assert.Equal(t, "Santiago Vila <sanvila@debian.org>", hello.Maintainer)
+	assert.Equal(t, "example package based on GNU hello", hello.Description)
+	assert.Equal(t, "27ad9bd1b7d8e9f7c2f7c4bd4d2ab1bd", hello.DescriptionMD5)
+	assert.Equal(t, "pool/main/h/hello/hello_2.10-3_amd64.deb", hello.Filename)
+	assert.Equal(t, int64(53028), hello.Size)
+	assert.Equal(t, "7a1d5b7b2e1a2a3b7c5c3d6f0e8a9b1c", hello.MD5sum)
+	assert.Equal(t, "6b8e1f4b5c3d2a1e0f9e8d7c6b5a4f3e2d1c0b9a8f7e6d5c4b3a2f1e0d9c8b7a", hello.SHA256)
+	assert.Equal(t, "", hello.SHA1)
+	assert.Equal(t, "libc6 (>= 2.34)", hello.Depends.String())
+	assert.Equal(t, "https://www.gnu.org/software/hello/", hello.Paragraph.Value("Homepage"))
+
+	libc := pkgs[1]
+	assert.Equal(t, "glibc", libc.Source)
+	assert.Equal(t, "2.36-9", libc.SourceVersion.String())
+	assert.Equal(t, "same", libc.MultiArch)
+
+	tzdata := pkgs[2]
+	assert.Equal(t, "tzdata-bookworm", tzdata.Provides.String())
+}
+
+func TestParsePackagesStanzaErrors(t *testing.T) {
+	const stanza = "Package: foo\nVersion: 1.0\nArchitecture: all\nFilename: pool/f/foo.deb\nSize: 10\n"
+	for _, tt := range []struct{ old, new string }{
+		{"Filename: pool/f/foo.deb\n", ""},
+		{"Size: 10\n", ""},
+		{"Size: 10\n", "Size: ten\n"},
+		{"Size: 10\n", "Size: 10\nInstalled-Size: -1\n"},
+		{"Size: 10\n", "Size: 10\nSource: bar (\n"},
+		{"Size: 10\n", "Size: 10\nSource: bar (x)\n"},
+	} {
+		_, err := NewPackagesReader(strings.NewReader(strings.Replace(stanza, tt.old, tt.new, 1))).Next()
+		assert.NotNil(t, err, tt.new)
+	}
+
+	p, err := NewPackagesReader(strings.NewReader(stanza)).Next()
+	if assert.Nil(t, err) {
+		assert.Equal(t, "foo", p.Source)
+	}
+}
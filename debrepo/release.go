@@ -0,0 +1,319 @@
+package debrepo
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/kelleyk/godebian/debcontrol"
+	"github.com/kelleyk/godebian/debfile"
+	"github.com/kelleyk/godebian/debpgp"
+	"github.com/pkg/errors"
+)
+
+// ReleaseFile is an entry in the checksum tables of a Release file, describing one of the index files of the
+// distribution.  Checksums that the Release file does not list are empty.
+type ReleaseFile struct {
+	// Path is relative to the directory that holds the Release file, e.g. "main/binary-amd64/Packages.xz".
+	Path string
+	debfile.Checksums
+}
+
+// Release holds the contents of a Release file (or of the signed part of an InRelease file).
+//
+// Ref.: https://wiki.debian.org/DebianRepository/Format#A.22Release.22_files
+type Release struct {
+	Origin      string
+	Label       string
+	Suite       string
+	Codename    string
+	Version     string
+	Description string
+	Date        time.Time
+	// ValidUntil is the zero time if the Release file has no Valid-Until field.
+	ValidUntil    time.Time
+	Architectures []string
+	Components    []string
+	// AcquireByHash is true if index files may be fetched by their hash, from "by-hash" directories.
+	AcquireByHash bool
+	// NoSupportForArchitectureAll is true if the Packages indices for architecture "all" are not duplicated into the
+	// indices for each of the other architectures.
+	NoSupportForArchitectureAll bool
+
+	Files []ReleaseFile
+
+	// Paragraph is the paragraph that the release was parsed from; it holds the fields not listed above.
+	Paragraph debcontrol.Paragraph
+}
+
+// releaseDateLayouts are the formats that dates in Release files are found in; they are RFC 2822 dates, but not
+// always with a two-digit day of the month or a numeric time zone.
+var releaseDateLayouts = []string{
+	"Mon, _2 Jan 2006 15:04:05 MST",
+	"Mon, _2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+}
+
+// ReleaseDateLayout is the format that Release files are written with.
+const ReleaseDateLayout = "Mon, 02 Jan 2006 15:04:05 MST"
+
+func parseReleaseDate(name, value string) (time.Time, error) {
+	for _, layout := range releaseDateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, errors.Errorf("malformed %v field: %q", name, value)
+}
+
+// ParseRelease parses a Release file.  The Suite (or Codename) and Date fields are required.
+//
+// To parse an InRelease file, use ParseInRelease, or verify it and parse the payload.
+func ParseRelease(r io.Reader) (*Release, error) {
+	p, err := debcontrol.NewReader(r).Next()
+	if err == io.EOF {
+		return nil, errors.New("release file is empty")
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse release file")
+	}
+
+	rel := &Release{
+		Origin:                      p.Value("Origin"),
+		Label:                       p.Value("Label"),
+		Suite:                       p.Value("Suite"),
+		Codename:                    p.Value("Codename"),
+		Version:                     p.Value("Version"),
+		Description:                 p.Value("Description"),
+		Architectures:               strings.Fields(p.Value("Architectures")),
+		Components:                  strings.Fields(p.Value("Components")),
+		AcquireByHash:               p.Value("Acquire-By-Hash") == "yes",
+		NoSupportForArchitectureAll: p.Value("No-Support-for-Architecture-all") == "Packages",
+		Paragraph:                   p,
+	}
+	if rel.Suite == "" && rel.Codename == "" {
+		return nil, errors.New("release file has neither a Suite nor a Codename field")
+	}
+
+	date, ok := p.Get("Date")
+	if !ok {
+		return nil, errors.New("release file is missing the Date field")
+	}
+	if rel.Date, err = parseReleaseDate("Date", date); err != nil {
+		return nil, err
+	}
+	if validUntil, ok := p.Get("Valid-Until"); ok {
+		if rel.ValidUntil, err = parseReleaseDate("Valid-Until", validUntil); err != nil {
+			return nil, err
+		}
+	}
+
+	if rel.Files, err = parseReleaseFiles(p); err != nil {
+		return nil, err
+	}
+	return rel, nil
+}
+
+// ParseInRelease parses the signed part of an InRelease file WITHOUT verifying the signature.
+func ParseInRelease(r io.Reader) (*Release, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	msg, err := debpgp.DecodeClearsigned(data)
+	if err != nil {
+		return nil, err
+	}
+	return ParseRelease(bytes.NewReader(msg.Text))
+}
+
+// VerifyInRelease verifies the signature of an InRelease file against kr and parses the signed part.  Only the signed
+// part is parsed, so nothing outside the signature can affect the result.
+func VerifyInRelease(r io.Reader, kr *debpgp.Keyring, p debpgp.Policy) (*Release, *debpgp.Signature, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	text, sig, err := kr.VerifyClearsigned(data, p)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to verify InRelease file")
+	}
+	rel, err := ParseRelease(bytes.NewReader(text))
+	if err != nil {
+		return nil, nil, err
+	}
+	return rel, sig, nil
+}
+
+// VerifyRelease verifies a Release file against its detached signature (a Release.gpg file) and parses it.
+func VerifyRelease(release, sig io.Reader, kr *debpgp.Keyring, p debpgp.Policy) (*Release, *debpgp.Signature, error) {
+	data, err := io.ReadAll(release)
+	if err != nil {
+		return nil, nil, err
+	}
+	sigData, err := io.ReadAll(sig)
+	if err != nil {
+		return nil, nil, err
+	}
+	good, err := kr.VerifyDetached(data, sigData, p)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to verify Release file")
+	}
+	rel, err := ParseRelease(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, err
+	}
+	return rel, good, nil
+}
+
+// parseReleaseFiles merges the checksum tables, which list the same files with different hashes.
+func parseReleaseFiles(p debcontrol.Paragraph) ([]ReleaseFile, error) {
+	var files []ReleaseFile
+	index := make(map[string]int)
+	for _, f := range []struct {
+		name string
+		hash func(*ReleaseFile) *string
+	}{
+		{"MD5Sum", func(rf *ReleaseFile) *string { return &rf.MD5 }},
+		{"SHA1", func(rf *ReleaseFile) *string { return &rf.SHA1 }},
+		{"SHA256", func(rf *ReleaseFile) *string { return &rf.SHA256 }},
+		{"SHA512", func(rf *ReleaseFile) *string { return &rf.SHA512 }},
+	} {
+		lines, err := parseChecksumTable(f.name, p.Value(f.name))
+		if err != nil {
+			return nil, err
+		}
+		for _, l := range lines {
+			i, ok := index[l.Name]
+			if !ok {
+				i = len(files)
+				index[l.Name] = i
+				files = append(files, ReleaseFile{Path: l.Name, Checksums: debfile.Checksums{Size: l.Size}})
+			}
+			if files[i].Size != l.Size {
+				return nil, errors.Errorf("%v gives a different size for %v (%d) than an earlier field (%d)", f.name,
+					l.Name, l.Size, files[i].Size)
+			}
+			*f.hash(&files[i]) = l.Hash
+		}
+	}
+	return files, nil
+}
+
+// String formats the release as a Release file.  Only the fields of Release are written; fields in Paragraph are not.
+func (r *Release) String() string {
+	var p debcontrol.Paragraph
+	for _, f := range []struct{ name, value string }{
+		{"Origin", r.Origin},
+		{"Label", r.Label},
+		{"Suite", r.Suite},
+		{"Codename", r.Codename},
+		{"Version", r.Version},
+		{"Description", r.Description},
+	} {
+		if f.value != "" {
+			p.Set(f.name, f.value)
+		}
+	}
+	p.Set("Date", r.Date.UTC().Format(ReleaseDateLayout))
+	if !r.ValidUntil.IsZero() {
+		p.Set("Valid-Until", r.ValidUntil.UTC().Format(ReleaseDateLayout))
+	}
+	if len(r.Architectures) > 0 {
+		p.Set("Architectures", strings.Join(r.Architectures, " "))
+	}
+	if len(r.Components) > 0 {
+		p.Set("Components", strings.Join(r.Components, " "))
+	}
+	if r.AcquireByHash {
+		p.Set("Acquire-By-Hash", "yes")
+	}
+	if r.NoSupportForArchitectureAll {
+		p.Set("No-Support-for-Architecture-all", "Packages")
+	}
+
+	for _, f := range []struct {
+		name string
+		hash func(*ReleaseFile) string
+	}{
+		{"MD5Sum", func(rf *ReleaseFile) string { return rf.MD5 }},
+		{"SHA1", func(rf *ReleaseFile) string { return rf.SHA1 }},
+		{"SHA256", func(rf *ReleaseFile) string { return rf.SHA256 }},
+		{"SHA512", func(rf *ReleaseFile) string { return rf.SHA512 }},
+	} {
+		var b strings.Builder
+		for i := range r.Files {
+			if h := f.hash(&r.Files[i]); h != "" {
+				fmt.Fprintf(&b, "\n%s %16d %s", h, r.Files[i].Size, r.Files[i].Path)
+			}
+		}
+		if b.Len() > 0 {
+			p.Set(f.name, b.String())
+		}
+	}
+	return p.String()
+}
+
+// File returns the entry for the index file at the given path (relative to the directory that holds the Release file).
+func (r *Release) File(path string) (*ReleaseFile, bool) {
+	for i := range r.Files {
+		if r.Files[i].Path == path {
+			return &r.Files[i], true
+		}
+	}
+	return nil, false
+}
+
+// VerifyFile checks that the contents of the index file at the given path match its size and every checksum that the
+// Release file lists for it.  Passing an MD5 or SHA1 match alone is not enough: if the Release file lists no SHA256 or
+// SHA512 checksum for the file, verification fails.
+func (r *Release) VerifyFile(path string, rd io.Reader) error {
+	f, ok := r.File(path)
+	if !ok {
+		return errors.Errorf("%v is not listed in the release file", path)
+	}
+	if f.SHA256 == "" && f.SHA512 == "" {
+		return errors.Errorf("release file lists no strong checksum for %v", path)
+	}
+
+	cs, err := debfile.ComputeChecksums(rd)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read %v", path)
+	}
+	if cs.Size != f.Size {
+		return errors.Errorf("%v has size %d; release file lists %d", path, cs.Size, f.Size)
+	}
+	for _, c := range []struct{ name, expected, actual string }{
+		{"MD5", f.MD5, cs.MD5},
+		{"SHA1", f.SHA1, cs.SHA1},
+		{"SHA256", f.SHA256, cs.SHA256},
+		{"SHA512", f.SHA512, cs.SHA512},
+	} {
+		if c.expected != "" && !strings.EqualFold(c.expected, c.actual) {
+			return errors.Errorf("%v has %v %v; release file lists %v", path, c.name, c.actual, c.expected)
+		}
+	}
+	return nil
+}
+
+// ByHashPath returns the path (relative to the directory that holds the Release file) from which the index file may be
+// fetched by its SHA256 hash when AcquireByHash is set, e.g. "main/binary-amd64/by-hash/SHA256/<hash>".
+func (f *ReleaseFile) ByHashPath() string {
+	return path.Join(path.Dir(f.Path), "by-hash", "SHA256", f.SHA256)
+}
+
+// CheckDates checks that the release is current at the time now: its Date must not be further than skew in the future
+// (which would suggest that one of the clocks is wrong), and its Valid-Until, if any, must not have passed.
+func (r *Release) CheckDates(now time.Time, skew time.Duration) error {
+	if r.Date.After(now.Add(skew)) {
+		return errors.Errorf("release file is dated in the future (%v)", r.Date.Format(ReleaseDateLayout))
+	}
+	if !r.ValidUntil.IsZero() && now.After(r.ValidUntil) {
+		return errors.Errorf("release file expired at %v", r.ValidUntil.Format(ReleaseDateLayout))
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+package debrepo
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kelleyk/godebian/debcontrol"
+	"github.com/kelleyk/godebian/debpgp"
+	"github.com/stretchr/testify/assert"
+)
+
+func loadTestRelease(t *testing.T) *Release {
+	f, err := os.Open("testdata/Release")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	rel, err := ParseRelease(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rel
+}
+
+func TestParseRelease(t *testing.T) {
+	rel := loadTestRelease(t)
+	assert.Equal(t, "Debian", rel.Origin)
+	assert.Equal(t, "Debian", rel.Label)
+	assert.Equal(t, "stable", rel.Suite)
+	assert.Equal(t, "bookworm", rel.Codename)
+	assert.Equal(t, "12.5", rel.Version)
+	assert.Equal(t, "Debian 12.5 Released 10 February 2024", rel.Description)
+	assert.Equal(t, time.Date(2024, 2, 10, 9, 46, 46, 0, time.UTC), rel.Date.UTC())
+	assert.Equal(t, time.Date(2024, 2, 17, 9, 46, 46, 0, time.UTC), rel.ValidUntil.UTC())
+	assert.Equal(t, []string{"all", "amd64", "arm64", "i386"}, rel.Architectures)
+	assert.Equal(t, []string{"main", "contrib", "non-free-firmware", "non-free"}, rel.Components)
+	assert.True(t, rel.AcquireByHash)
+	assert.True(t, rel.NoSupportForArchitectureAll)
+	assert.Equal(t, "https://metadata.ftp-master.debian.org/changelogs/@CHANGEPATH@_changelog",
+		rel.Paragraph.Value("Changelogs"))
+
+	assert.Equal(t, 5, len(rel.Files))
+	f, ok := rel.File("main/binary-amd64/Packages.xz")
+	if assert.True(t, ok) {
+		assert.Equal(t, int64(996), f.Size)
+		assert.Equal(t, "5e66b5661bdf0d252b050843f9b98021", f.MD5)
+		assert.Equal(t, "f095bebb6df2fba92887d782beefde1b7f84f6d1df04374679f9481871e43fbf", f.SHA256)
+		assert.Equal(t, "", f.SHA1)
+		assert.Equal(t,
+			"main/binary-amd64/by-hash/SHA256/f095bebb6df2fba92887d782beefde1b7f84f6d1df04374679f9481871e43fbf",
+			f.ByHashPath())
+	}
+	_, ok = rel.File("main/binary-arm64/Packages.xz")
+	assert.False(t, ok)
+}
+
+func TestParseInRelease(t *testing.T) {
+	f, err := os.Open("testdata/InRelease")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	rel, err := ParseInRelease(f)
+	if assert.Nil(t, err) {
+		assert.Equal(t, loadTestRelease(t), rel)
+	}
+
+	_, err = ParseInRelease(strings.NewReader("Suite: stable\n"))
+	assert.NotNil(t, err)
+}
+
+func TestParseReleaseErrors(t *testing.T) {
+	const release = "Suite: stable\nDate: Sat, 10 Feb 2024 09:46:46 UTC\nSHA256:\n abcd 10 main/Packages\n"
+	for _, tt := range []struct{ old, new string }{
+		{release, ""},
+		{"Suite: stable\n", ""},
+		{"Date: Sat, 10 Feb 2024 09:46:46 UTC\n", ""},
+		{"Sat, 10 Feb 2024", "2024-02-10"},
+		{"SHA256:", "Valid-Until: tomorrow\nSHA256:"},
+		{" abcd 10", " abcd ten"},
+		{"SHA256:", "MD5Sum:\n 1234 11 main/Packages\nSHA256:"},
+	} {
+		_, err := ParseRelease(strings.NewReader(strings.Replace(release, tt.old, tt.new, 1)))
+		assert.NotNil(t, err, tt.new)
+	}
+
+	rel, err := ParseRelease(strings.NewReader(strings.Replace(release, "10 Feb", "1 Feb", 1)))
+	if assert.Nil(t, err) {
+		assert.Equal(t, 1, rel.Date.Day())
+		assert.True(t, rel.ValidUntil.IsZero())
+	}
+}
+
+func TestVerifyFile(t *testing.T) {
+	rel := loadTestRelease(t)
+	for _, tt := range []struct{ path, file string }{
+		{"main/binary-amd64/Packages", "testdata/Packages"},
+		{"main/binary-amd64/Packages.xz", "testdata/Packages.xz"},
+		{"main/source/Sources", "testdata/Sources"},
+	} {
+		data, err := os.ReadFile(tt.file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Nil(t, rel.VerifyFile(tt.path, bytes.NewReader(data)), tt.path)
+
+		// The wrong contents, with and without the right size.
+		assert.NotNil(t, rel.VerifyFile(tt.path, bytes.NewReader(data[1:])), tt.path)
+		data[0] ^= 1
+		assert.NotNil(t, rel.VerifyFile(tt.path, bytes.NewReader(data)), tt.path)
+	}
+
+	assert.NotNil(t, rel.VerifyFile("main/binary-arm64/Packages", strings.NewReader("")))
+
+	weak, err := ParseRelease(strings.NewReader("Suite: s\nDate: Sat, 10 Feb 2024 09:46:46 UTC\n" +
+		"MD5Sum:\n d41d8cd98f00b204e9800998ecf8427e 0 Packages\n"))
+	if assert.Nil(t, err) {
+		assert.NotNil(t, weak.VerifyFile("Packages", strings.NewReader("")))
+	}
+}
+
+func TestCheckDates(t *testing.T) {
+	rel := loadTestRelease(t)
+	date := time.Date(2024, 2, 10, 9, 46, 46, 0, time.UTC)
+
+	assert.Nil(t, rel.CheckDates(date, 0))
+	assert.Nil(t, rel.CheckDates(date.Add(6*24*time.Hour), 0))
+	assert.NotNil(t, rel.CheckDates(date.Add(8*24*time.Hour), 0))
+	assert.NotNil(t, rel.CheckDates(date.Add(-time.Minute), 0))
+	assert.Nil(t, rel.CheckDates(date.Add(-time.Minute), 5*time.Minute))
+
+	rel.ValidUntil = time.Time{}
+	assert.Nil(t, rel.CheckDates(date.Add(365*24*time.Hour), 0))
+}
+
+func TestVerifyInRelease(t *testing.T) {
+	kr, err := debpgp.LoadKeyring("testdata/archive-key.asc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile("testdata/InRelease")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rel, sig, err := VerifyInRelease(bytes.NewReader(data), kr, debpgp.Policy{})
+	if assert.Nil(t, err) {
+		assert.Equal(t, "stable", rel.Suite)
+		assert.Equal(t, kr.Fingerprints()[0], sig.PrimaryFingerprint)
+	}
+
+	tampered := bytes.Replace(data, []byte("Suite: stable"), []byte("Suite: unstable"), 1)
+	_, _, err = VerifyInRelease(bytes.NewReader(tampered), kr, debpgp.Policy{})
+	assert.NotNil(t, err)
+	_, _, err = VerifyInRelease(bytes.NewReader(data), &debpgp.Keyring{}, debpgp.Policy{})
+	assert.NotNil(t, err)
+}
+
+func TestVerifyRelease(t *testing.T) {
+	kr, err := debpgp.LoadKeyring("testdata/archive-key.asc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile("testdata/Release")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := os.ReadFile("testdata/Release.gpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rel, good, err := VerifyRelease(bytes.NewReader(data), bytes.NewReader(sig), kr, debpgp.Policy{})
+	if assert.Nil(t, err) {
+		assert.Equal(t, "bookworm", rel.Codename)
+		assert.Equal(t, kr.Fingerprints()[0], good.Fingerprint)
+	}
+
+	tampered := bytes.Replace(data, []byte("Suite: stable"), []byte("Suite: unstable"), 1)
+	_, _, err = VerifyRelease(bytes.NewReader(tampered), bytes.NewReader(sig), kr, debpgp.Policy{})
+	assert.NotNil(t, err)
+}
+
+func TestReleaseString(t *testing.T) {
+	rel := loadTestRelease(t)
+	out, err := ParseRelease(strings.NewReader(rel.String()))
+	if assert.Nil(t, err) {
+		out.Paragraph, rel.Paragraph = debcontrol.Paragraph{}, debcontrol.Paragraph{}
+		assert.Equal(t, rel, out)
+	}
+	assert.Contains(t, rel.String(), "Date: Sat, 10 Feb 2024 09:46:46 UTC\n")
+}
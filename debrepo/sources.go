@@ -0,0 +1,252 @@
+package debrepo
+
+import (
+	"io"
+	"strings"
+
+	"github.com/kelleyk/godebian/debcontrol"
+	"github.com/kelleyk/godebian/debrelation"
+	"github.com/kelleyk/godebian/debversion"
+	"github.com/pkg/errors"
+)
+
+// SourceFile is one of the files that make up a source package, as listed in the Files and Checksums-* fields.
+type SourceFile struct {
+	Name   string
+	Size   int64
+	MD5    string
+	SHA1   string
+	SHA256 string
+	SHA512 string
+}
+
+// PackageListEntry is a line of the Package-List field, which describes one of the binary packages that a source
+// package builds, e.g. "libfoo1 deb libs optional arch=any".
+type PackageListEntry struct {
+	Package  string
+	Type     string
+	Section  string
+	Priority string
+	// Architectures is the value of the "arch" option, split on commas.
+	Architectures []string
+	// Options holds the remaining "key=value" options, such as "profile" and "essential".
+	Options map[string]string
+}
+
+// SourcePackage is a stanza from a Sources index.
+type SourcePackage struct {
+	Package string
+	Version debversion.DebianVersion
+	// Binary lists the names of the binary packages that the source package builds.
+	Binary        []string
+	Architectures []string
+	Format        string
+	Maintainer    string
+	Uploaders     string
+	Section       string
+	Priority      string
+	Homepage      string
+	// Directory is the directory that holds the files of the source package, relative to the root of the repository.
+	Directory string
+	Files     []SourceFile
+	// Testsuite lists the values of the Testsuite field (e.g. "autopkgtest").
+	Testsuite   []string
+	PackageList []PackageListEntry
+
+	// VcsBrowser is the value of the Vcs-Browser field.  VcsType and VcsURL come from the other Vcs-* field, if any:
+	// for "Vcs-Git: https://salsa.debian.org/foo.git", VcsType is "Git".
+	VcsBrowser string
+	VcsType    string
+	VcsURL     string
+
+	BuildDepends        debrelation.Relations
+	BuildDependsArch    debrelation.Relations
+	BuildDependsIndep   debrelation.Relations
+	BuildConflicts      debrelation.Relations
+	BuildConflictsArch  debrelation.Relations
+	BuildConflictsIndep debrelation.Relations
+
+	// Paragraph is the paragraph that the package was parsed from; it holds the fields not listed above.
+	Paragraph debcontrol.Paragraph
+}
+
+// String identifies the source package by name and version (e.g. "glibc_2.36-9").
+func (s *SourcePackage) String() string {
+	return s.Package + "_" + s.Version.String()
+}
+
+// Builds reports whether the source package builds the named binary package.
+func (s *SourcePackage) Builds(binary string) bool {
+	for _, b := range s.Binary {
+		if b == binary {
+			return true
+		}
+	}
+	return false
+}
+
+// BuildRelations reduces the build relationship fields for a particular build; see debcontrol.ReduceBuildRelations.
+func (s *SourcePackage) BuildRelations(c debrelation.BuildContext, arch, indep bool) (*debcontrol.BuildRelations, error) {
+	return debcontrol.ReduceBuildRelations(s.Paragraph, c, arch, indep)
+}
+
+// ParseSourcesStanza builds a SourcePackage from a stanza of a Sources index.  The Package and Version fields are
+// required.
+func ParseSourcesStanza(p debcontrol.Paragraph) (*SourcePackage, error) {
+	s := &SourcePackage{
+		Package:       p.Value("Package"),
+		Binary:        splitList(p.Value("Binary")),
+		Architectures: strings.Fields(p.Value("Architecture")),
+		Format:        p.Value("Format"),
+		Maintainer:    p.Value("Maintainer"),
+		Uploaders:     p.Value("Uploaders"),
+		Section:       p.Value("Section"),
+		Priority:      p.Value("Priority"),
+		Homepage:      p.Value("Homepage"),
+		Directory:     p.Value("Directory"),
+		Testsuite:     splitList(p.Value("Testsuite")),
+		VcsBrowser:    p.Value("Vcs-Browser"),
+		Paragraph:     p,
+	}
+	for _, name := range []string{"Package", "Version"} {
+		if _, ok := p.Get(name); !ok {
+			return nil, errors.Errorf("source package is missing the %v field", name)
+		}
+	}
+
+	v, err := debversion.FromString(p.Value("Version"))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse version of %v", s.Package)
+	}
+	s.Version = v
+
+	for _, f := range p.Fields {
+		if t := strings.TrimPrefix(f.Name, "Vcs-"); t != f.Name && t != "Browser" {
+			s.VcsType, s.VcsURL = t, strings.TrimSpace(f.Value)
+		}
+	}
+
+	for _, f := range []struct {
+		name string
+		dst  *debrelation.Relations
+	}{
+		{"Build-Depends", &s.BuildDepends},
+		{"Build-Depends-Arch", &s.BuildDependsArch},
+		{"Build-Depends-Indep", &s.BuildDependsIndep},
+		{"Build-Conflicts", &s.BuildConflicts},
+		{"Build-Conflicts-Arch", &s.BuildConflictsArch},
+		{"Build-Conflicts-Indep", &s.BuildConflictsIndep},
+	} {
+		rs, err := debrelation.Parse(p.Value(f.name))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse %v of %v", f.name, s)
+		}
+		*f.dst = rs
+	}
+
+	if s.Files, err = parseSourceFiles(p); err != nil {
+		return nil, errors.Wrapf(err, "source package %v", s)
+	}
+	if s.PackageList, err = parsePackageList(p.Value("Package-List")); err != nil {
+		return nil, errors.Wrapf(err, "source package %v", s)
+	}
+
+	return s, nil
+}
+
+// parseSourceFiles merges the Files and Checksums-* tables, which list the same files with different hashes.
+func parseSourceFiles(p debcontrol.Paragraph) ([]SourceFile, error) {
+	var files []SourceFile
+	index := make(map[string]int)
+	for _, f := range []struct {
+		name string
+		hash func(*SourceFile) *string
+	}{
+		{"Files", func(sf *SourceFile) *string { return &sf.MD5 }},
+		{"Checksums-Sha1", func(sf *SourceFile) *string { return &sf.SHA1 }},
+		{"Checksums-Sha256", func(sf *SourceFile) *string { return &sf.SHA256 }},
+		{"Checksums-Sha512", func(sf *SourceFile) *string { return &sf.SHA512 }},
+	} {
+		lines, err := parseChecksumTable(f.name, p.Value(f.name))
+		if err != nil {
+			return nil, err
+		}
+		for _, l := range lines {
+			i, ok := index[l.Name]
+			if !ok {
+				i = len(files)
+				index[l.Name] = i
+				files = append(files, SourceFile{Name: l.Name, Size: l.Size})
+			}
+			if files[i].Size != l.Size {
+				return nil, errors.Errorf("%v gives a different size for %v (%d) than an earlier field (%d)", f.name,
+					l.Name, l.Size, files[i].Size)
+			}
+			*f.hash(&files[i]) = l.Hash
+		}
+	}
+	return files, nil
+}
+
+func parsePackageList(value string) ([]PackageListEntry, error) {
+	var entries []PackageListEntry
+	for _, line := range strings.Split(value, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) < 4 {
+			return nil, errors.Errorf("malformed line in Package-List field: %q", line)
+		}
+		e := PackageListEntry{Package: fields[0], Type: fields[1], Section: fields[2], Priority: fields[3]}
+		for _, opt := range fields[4:] {
+			k, v, ok := strings.Cut(opt, "=")
+			if !ok {
+				return nil, errors.Errorf("malformed option in Package-List field: %q", opt)
+			}
+			if k == "arch" {
+				e.Architectures = strings.Split(v, ",")
+				continue
+			}
+			if e.Options == nil {
+				e.Options = make(map[string]string)
+			}
+			e.Options[k] = v
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// SourcesReader reads the stanzas of a Sources index one at a time.
+type SourcesReader struct {
+	idx *indexFile
+}
+
+// NewSourcesReader returns a reader for an uncompressed Sources index.
+func NewSourcesReader(r io.Reader) *SourcesReader {
+	return &SourcesReader{idx: &indexFile{r: debcontrol.NewReader(r)}}
+}
+
+// OpenSources opens a Sources index file, which is decompressed according to its extension (e.g. "Sources.xz").
+func OpenSources(path string) (*SourcesReader, error) {
+	idx, err := openIndex(path)
+	if err != nil {
+		return nil, err
+	}
+	return &SourcesReader{idx: idx}, nil
+}
+
+// Next returns the next source package in the index.  It returns io.EOF when there are no more packages.
+func (r *SourcesReader) Next() (*SourcePackage, error) {
+	p, err := r.idx.r.Next()
+	if err != nil {
+		return nil, err
+	}
+	return ParseSourcesStanza(p)
+}
+
+// Close releases the resources held by a reader returned by OpenSources.
+func (r *SourcesReader) Close() error {
+	return r.idx.Close()
+}
@@ -0,0 +1,110 @@
+package debrepo
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/kelleyk/godebian/debrelation"
+	"github.com/stretchr/testify/assert"
+)
+
+func readAllSources(t *testing.T, r *SourcesReader) []*SourcePackage {
+	var srcs []*SourcePackage
+	for {
+		s, err := r.Next()
+		if err == io.EOF {
+			return srcs
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		srcs = append(srcs, s)
+	}
+}
+
+func TestOpenSources(t *testing.T) {
+	for _, name := range []string{"Sources", "Sources.xz"} {
+		r, err := OpenSources("testdata/" + name)
+		if !assert.Nil(t, err, name) {
+			continue
+		}
+		srcs := readAllSources(t, r)
+		assert.Nil(t, r.Close())
+		if assert.Equal(t, 2, len(srcs), name) {
+			assert.Equal(t, "hello_2.10-3", srcs[0].String())
+			assert.Equal(t, "glibc_2.36-9+deb12u4", srcs[1].String())
+		}
+	}
+}
+
+func TestParseSourcesStanza(t *testing.T) {
+	r, err := OpenSources("testdata/Sources")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	srcs := readAllSources(t, r)
+
+	hello := srcs[0]
+	assert.Equal(t, []string{"hello"}, hello.Binary)
+	assert.Equal(t, []string{"any"}, hello.Architectures)
+	assert.Equal(t, "3.0 (quilt)", hello.Format)
+	assert.Equal(t, "pool/main/h/hello", hello.Directory)
+	assert.Equal(t, []string{"autopkgtest"}, hello.Testsuite)
+	assert.Equal(t, "https://salsa.debian.org/sanvila/hello", hello.VcsBrowser)
+	assert.Equal(t, "Git", hello.VcsType)
+	assert.Equal(t, "https://salsa.debian.org/sanvila/hello.git", hello.VcsURL)
+	assert.Equal(t, "debhelper-compat (= 13), help2man, texinfo", hello.BuildDepends.String())
+	assert.Equal(t, []SourceFile{
+		{Name: "hello_2.10-3.dsc", Size: 1183, MD5: "a9b8c7d6e5f40312a9b8c7d6e5f40312",
+			SHA256: "1111111111111111111111111111111111111111111111111111111111111111"},
+		{Name: "hello_2.10.orig.tar.gz", Size: 725946, MD5: "6cd0ffea3884a4e79330338dcc2987d6",
+			SHA256: "31e066137a962676e89f69d1b65382de95a7ef7d914b8cb956f41ea72e0f516b"},
+		{Name: "hello_2.10-3.debian.tar.xz", Size: 12688, MD5: "0f1e2d3c4b5a69788796a5b4c3d2e1f0",
+			SHA256: "2222222222222222222222222222222222222222222222222222222222222222"},
+	}, hello.Files)
+	assert.Equal(t, []PackageListEntry{
+		{Package: "hello", Type: "deb", Section: "devel", Priority: "optional", Architectures: []string{"any"}},
+	}, hello.PackageList)
+
+	glibc := srcs[1]
+	assert.True(t, glibc.Builds("libc6"))
+	assert.False(t, glibc.Builds("hello"))
+	assert.Equal(t, []string{"any", "all"}, glibc.Architectures)
+	assert.Equal(t, "Svn", glibc.VcsType)
+	assert.Equal(t, "", glibc.VcsBrowser)
+	assert.Equal(t, "systemtap-sdt-dev [linux-any]", glibc.BuildDependsArch.String())
+	assert.Equal(t, "gcc-multilib", glibc.BuildConflicts.String())
+	if assert.Equal(t, 6, len(glibc.PackageList)) {
+		assert.Equal(t, map[string]string{"essential": "yes"}, glibc.PackageList[0].Options)
+		assert.Equal(t, []string{"amd64", "i386"}, glibc.PackageList[2].Architectures)
+		assert.Equal(t, map[string]string{"profile": "!stage1"}, glibc.PackageList[2].Options)
+	}
+
+	br, err := glibc.BuildRelations(debrelation.BuildContext{HostArch: "i386", BuildArch: "i386"}, true, false)
+	if assert.Nil(t, err) {
+		assert.Equal(t, "gettext, dpkg-dev (>= 1.18.8), bison, gcc-12, systemtap-sdt-dev", br.AllDepends().String())
+	}
+}
+
+func TestParseSourcesStanzaErrors(t *testing.T) {
+	const stanza = "Package: foo\nVersion: 1.0\nFiles:\n 0123 10 foo.dsc\nChecksums-Sha256:\n 4567 10 foo.dsc\n"
+	for _, tt := range []struct{ old, new string }{
+		{"Version: 1.0\n", ""},
+		{"Version: 1.0\n", "Version: x\n"},
+		{" 4567 10 foo.dsc", " 4567 11 foo.dsc"},
+		{" 4567 10 foo.dsc", " 4567 foo.dsc"},
+		{"Files:", "Build-Depends: foo (>= \nFiles:"},
+		{"Files:", "Package-List: \n foo deb\nFiles:"},
+		{"Files:", "Package-List: \n foo deb libs optional arch\nFiles:"},
+	} {
+		_, err := NewSourcesReader(strings.NewReader(strings.Replace(stanza, tt.old, tt.new, 1))).Next()
+		assert.NotNil(t, err, tt.new)
+	}
+
+	s, err := NewSourcesReader(strings.NewReader(stanza)).Next()
+	if assert.Nil(t, err) {
+		assert.Equal(t, []SourceFile{{Name: "foo.dsc", Size: 10, MD5: "0123", SHA256: "4567"}}, s.Files)
+	}
+}
@@ -0,0 +1,282 @@
+package debresolve
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kelleyk/godebian/debcontrol"
+	"github.com/kelleyk/godebian/debrelation"
+	"github.com/stretchr/testify/assert"
+)
+
+const testMultiArchPackages = `
+Package: libc6
+Version: 2.36
+Architecture: amd64
+Multi-Arch: same
+
+Package: libc6
+Version: 2.36
+Architecture: i386
+Multi-Arch: same
+
+Package: libc6
+Version: 2.35
+Architecture: arm64
+Multi-Arch: same
+
+Package: libfoo1
+Version: 1.0
+Architecture: i386
+Multi-Arch: same
+Depends: libc6
+
+Package: libfoo1
+Version: 1.0
+Architecture: amd64
+Multi-Arch: same
+Depends: libc6
+
+Package: python3
+Version: 3.11
+Architecture: amd64
+Multi-Arch: allowed
+Depends: libc6
+
+Package: python3
+Version: 3.11
+Architecture: arm64
+Multi-Arch: allowed
+Depends: libc6
+
+Package: perl
+Version: 5.36
+Architecture: amd64
+Depends: libc6
+
+Package: make
+Version: 4.3
+Architecture: amd64
+Multi-Arch: foreign
+Depends: libc6
+
+Package: data-files
+Version: 1.0
+Architecture: all
+Depends: libfoo1
+
+Package: i386-app
+Version: 1.0
+Architecture: i386
+Depends: libfoo1, make, python3:any
+
+Package: i386-perl-app
+Version: 1.0
+Architecture: i386
+Depends: perl
+
+Package: arm64-app
+Version: 1.0
+Architecture: arm64
+Depends: libc6 (>= 2.36)
+`
+
+func testMultiArchUniverse(t *testing.T) *Universe {
+	ps, err := debcontrol.ParseParagraphs(strings.NewReader(testMultiArchPackages))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pkgs []*debcontrol.BinaryPackage
+	for _, p := range ps {
+		b, err := debcontrol.ParseBinaryPackage(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pkgs = append(pkgs, b)
+	}
+	return NewUniverseWithOptions(pkgs, Options{NativeArch: "amd64"})
+}
+
+func archNames(ps []*debcontrol.BinaryPackage) []string {
+	var names []string
+	for _, p := range ps {
+		names = append(names, p.Package+":"+p.Architecture)
+	}
+	return names
+}
+
+func TestMultiArchWhatProvides(t *testing.T) {
+	u := testMultiArchUniverse(t)
+	rel := func(s string) debrelation.Relation { return mustParse(t, s)[0][0] }
+
+	// Unqualified dependencies are satisfied by the same architecture (treating "all" as native).
+	assert.Equal(t, []string{"libc6:amd64"}, archNames(u.WhatProvides(rel("libc6"), "")))
+	assert.Equal(t, []string{"libc6:i386"}, archNames(u.WhatProvides(rel("libc6"), "i386")))
+	assert.Equal(t, []string{"libfoo1:amd64"}, archNames(u.WhatProvides(rel("libfoo1"), "all")))
+
+	// Multi-Arch: foreign packages satisfy dependencies from any architecture.
+	assert.Equal(t, []string{"make:amd64"}, archNames(u.WhatProvides(rel("make"), "i386")))
+	assert.Nil(t, u.WhatProvides(rel("perl"), "i386"))
+
+	// :any is satisfied by Multi-Arch: allowed packages of any architecture.
+	assert.Equal(t, []string{"python3:amd64", "python3:arm64"}, archNames(u.WhatProvides(rel("python3:any"), "i386")))
+	assert.Nil(t, u.WhatProvides(rel("perl:any"), "i386"))
+	assert.Equal(t, []string{"python3:amd64"}, archNames(u.WhatProvides(rel("python3"), "amd64")))
+
+	// :native and explicit architectures.
+	assert.Equal(t, []string{"python3:amd64"}, archNames(u.WhatProvides(rel("python3:native"), "arm64")))
+	assert.Equal(t, []string{"libc6:i386"}, archNames(u.WhatProvides(rel("libc6:i386"), "")))
+
+	// Conflicts apply across architectures.
+	assert.Equal(t, []string{"libc6:amd64", "libc6:i386", "libc6:arm64"}, archNames(u.WhatConflicts(rel("libc6"))))
+	assert.Equal(t, []string{"libc6:arm64"}, archNames(u.WhatConflicts(rel("libc6:arm64"))))
+}
+
+func TestArchSatisfies(t *testing.T) {
+	var pkgs []*debcontrol.BinaryPackage
+	for _, ma := range []string{debcontrol.MultiArchNo, debcontrol.MultiArchSame, debcontrol.MultiArchForeign,
+		debcontrol.MultiArchAllowed} {
+		for _, arch := range []string{"amd64", "i386", "arm64"} {
+			pkgs = append(pkgs, &debcontrol.BinaryPackage{Package: ma, Architecture: arch, MultiArch: ma})
+		}
+	}
+	pkgs = append(pkgs, &debcontrol.BinaryPackage{Package: "indep", Architecture: "all", MultiArch: "no"})
+	u := NewUniverseWithOptions(pkgs, Options{NativeArch: "amd64"})
+
+	for _, tc := range []struct {
+		rel      string
+		fromArch string
+		expected []string
+	}{
+		// Unqualified relations refer to the architecture of the depending package, unless the candidate is foreign.
+		{"no", "", []string{"no:amd64"}},
+		{"no", "all", []string{"no:amd64"}},
+		{"no", "i386", []string{"no:i386"}},
+		{"same", "arm64", []string{"same:arm64"}},
+		{"foreign", "i386", []string{"foreign:amd64", "foreign:i386", "foreign:arm64"}},
+		{"allowed", "arm64", []string{"allowed:arm64"}},
+		{"indep", "amd64", []string{"indep:all"}},
+		{"indep", "i386", nil},
+
+		// :any requires Multi-Arch: allowed, even for the same architecture.
+		{"no:any", "amd64", nil},
+		{"same:any", "i386", nil},
+		{"foreign:any", "amd64", nil},
+		{"allowed:any", "i386", []string{"allowed:amd64", "allowed:i386", "allowed:arm64"}},
+
+		// :native and explicit qualifiers are not relaxed by Multi-Arch: foreign.
+		{"no:native", "i386", []string{"no:amd64"}},
+		{"foreign:native", "arm64", []string{"foreign:amd64"}},
+		{"allowed:native", "i386", []string{"allowed:amd64"}},
+		{"indep:native", "arm64", []string{"indep:all"}},
+		{"no:i386", "amd64", []string{"no:i386"}},
+		{"foreign:arm64", "i386", []string{"foreign:arm64"}},
+		{"allowed:arm64", "", []string{"allowed:arm64"}},
+		{"same:amd64", "arm64", []string{"same:amd64"}},
+	} {
+		r := mustParse(t, tc.rel)[0][0]
+		assert.Equal(t, tc.expected, archNames(u.WhatProvides(r, tc.fromArch)), "%s from %q", tc.rel, tc.fromArch)
+	}
+}
+
+func TestMultiArchResolve(t *testing.T) {
+	u := testMultiArchUniverse(t)
+
+	sol, err := u.Resolve(mustParse(t, "i386-app:i386, data-files"))
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{
+			"data-files:all", "i386-app:i386", "libc6:amd64", "libc6:i386", "libfoo1:amd64", "libfoo1:i386",
+			"make:amd64", "python3:amd64",
+		}, archNames(sol.Packages))
+	}
+
+	_, err = u.Resolve(mustParse(t, "i386-perl-app:i386"))
+	assert.IsType(t, &UnsatisfiableError{}, err)
+
+	// libc6:arm64 is too old, and Multi-Arch: same packages must have the same version on every architecture.
+	_, err = u.Resolve(mustParse(t, "arm64-app:arm64"))
+	assert.IsType(t, &UnsatisfiableError{}, err)
+	_, err = u.Resolve(mustParse(t, "libc6:arm64, libc6"))
+	if assert.IsType(t, &UnsatisfiableError{}, err) {
+		assert.Contains(t, err.(*UnsatisfiableError).Reasons,
+			"libc6_2.35_arm64 and libc6_2.36_amd64 cannot both be installed")
+	}
+
+	// Without Multi-Arch: same, only one architecture of a package may be installed.
+	_, err = u.Resolve(mustParse(t, "python3:amd64, python3:arm64"))
+	assert.IsType(t, &UnsatisfiableError{}, err)
+}
+
+const testCrossPackages = `
+Package: gcc-aarch64-linux-gnu
+Version: 12
+Architecture: amd64
+Multi-Arch: foreign
+
+Package: libssl-dev
+Version: 3.0
+Architecture: arm64
+Multi-Arch: same
+
+Package: libssl-dev
+Version: 3.0
+Architecture: amd64
+Multi-Arch: same
+
+Package: python3
+Version: 3.11
+Architecture: amd64
+Multi-Arch: allowed
+
+Package: python3
+Version: 3.11
+Architecture: arm64
+Multi-Arch: allowed
+
+Package: bison
+Version: 3.8
+Architecture: amd64
+Multi-Arch: foreign
+
+Package: libfoo-old-dev
+Version: 1.0
+Architecture: arm64
+`
+
+func TestResolveBuild(t *testing.T) {
+	ps, err := debcontrol.ParseParagraphs(strings.NewReader(testCrossPackages))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pkgs []*debcontrol.BinaryPackage
+	for _, p := range ps {
+		b, err := debcontrol.ParseBinaryPackage(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pkgs = append(pkgs, b)
+	}
+	u := NewUniverseWithOptions(pkgs, Options{NativeArch: "amd64"})
+	ctx := debrelation.BuildContext{HostArch: "arm64", BuildArch: "amd64"}
+
+	b := &debcontrol.BuildRelations{
+		Context: ctx,
+		Depends: mustParse(t, "gcc-aarch64-linux-gnu, libssl-dev, python3:native, bison"),
+	}
+	sol, err := u.ResolveBuild(b)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"bison:amd64", "gcc-aarch64-linux-gnu:amd64", "libssl-dev:arm64", "python3:amd64"},
+			archNames(sol.Packages))
+	}
+
+	b.Depends = mustParse(t, "libfoo-old-dev")
+	b.Conflicts = mustParse(t, "libfoo-old-dev")
+	_, err = u.ResolveBuild(b)
+	if assert.IsType(t, &UnsatisfiableError{}, err) {
+		assert.Contains(t, err.(*UnsatisfiableError).Reasons, "libfoo-old-dev is forbidden (libfoo-old-dev_1.0_arm64)")
+	}
+
+	b.Context.BuildArch = "i386"
+	_, err = u.ResolveBuild(b)
+	assert.Error(t, err)
+}
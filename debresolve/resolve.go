@@ -0,0 +1,407 @@
+package debresolve
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kelleyk/godebian/debcontrol"
+	"github.com/kelleyk/godebian/debrelation"
+	"github.com/pkg/errors"
+)
+
+// maxDecisions bounds the number of decisions made by a single search, so that pathological universes produce an
+// error rather than running (effectively) forever.
+const maxDecisions = 1000000
+
+// maxExplainWork bounds the total work, counted in clauses examined during propagation, that is spent looking for a
+// minimal explanation of why a request is unsatisfiable.  Finding one takes a solver call per clause, which gets slow
+// on large problems.
+const maxExplainWork = 100000000
+
+// ErrSearchLimit is returned when the resolver gives up before determining whether the request can be satisfied.
+var ErrSearchLimit = errors.New("dependency resolution exceeded its search limit")
+
+// UnsatisfiableError is returned when a request cannot be satisfied.  Reasons is a minimal set of constraints that
+// cannot all hold at once: dropping any one of them would make the rest satisfiable.
+type UnsatisfiableError struct {
+	Reasons []string
+	// Incomplete is set if the resolver gave up on making Reasons minimal; they still cannot all hold at once, but
+	// some of them may be irrelevant.
+	Incomplete bool
+}
+
+func (e *UnsatisfiableError) Error() string {
+	msg := "unsatisfiable dependencies:\n  - " + strings.Join(e.Reasons, "\n  - ")
+	if e.Incomplete {
+		msg += "\n(some of these constraints may be irrelevant)"
+	}
+	return msg
+}
+
+// Solution is a set of packages that can be installed together and that satisfies a request.
+type Solution struct {
+	// Packages is sorted by name.
+	Packages []*debcontrol.BinaryPackage
+}
+
+// Resolve finds a set of packages from the universe that satisfies every group of alternatives in goals, along with
+// the Depends and Pre-Depends of every package in the set, without violating any Conflicts or Breaks and without
+// including more than one version of any package.  Alternatives are preferred in the order in which they are written,
+// and higher versions are preferred to lower ones.
+//
+// If there is no such set, the error is an *UnsatisfiableError.
+func (u *Universe) Resolve(goals debrelation.Relations) (*Solution, error) {
+	return u.resolve(goals, "", nil)
+}
+
+// ResolveBuild finds a set of packages that satisfies a source package's build dependencies without violating its
+// build conflicts.  The universe's native architecture must be the build architecture; relations are evaluated as
+// though they came from a package of the host architecture, so that when cross-building, unqualified build
+// dependencies are satisfied by host-architecture packages (or by "Multi-Arch: foreign" ones) and ":native" ones by
+// build-architecture packages.
+func (u *Universe) ResolveBuild(b *debcontrol.BuildRelations) (*Solution, error) {
+	if u.opts.NativeArch != "" && u.opts.NativeArch != b.Context.BuildArch {
+		return nil, errors.Errorf("universe has native architecture %v, but the build architecture is %v",
+			u.opts.NativeArch, b.Context.BuildArch)
+	}
+	return u.resolve(b.AllDepends(), b.Context.HostArch, b.AllConflicts())
+}
+
+func (u *Universe) resolve(goals debrelation.Relations, fromArch string, conflicts debrelation.Relations) (*Solution,
+	error) {
+
+	pr := u.buildProblem(goals, fromArch, conflicts)
+
+	s := newSolver(pr.clauses, len(pr.vars))
+	ok, err := s.solve()
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		reasons, minimal := pr.explain()
+		return nil, &UnsatisfiableError{Reasons: reasons, Incomplete: !minimal}
+	}
+
+	sol := &Solution{}
+	for i, p := range pr.vars {
+		if s.assign[i] == valTrue {
+			sol.Packages = append(sol.Packages, p)
+		}
+	}
+	sort.Slice(sol.Packages, func(i, j int) bool {
+		a, b := sol.Packages[i], sol.Packages[j]
+		if a.Package != b.Package {
+			return a.Package < b.Package
+		}
+		return a.Architecture < b.Architecture
+	})
+	return sol, nil
+}
+
+// A literal is a variable index plus one, negated to indicate that the package must not be installed.
+type literal int
+
+func (l literal) variable() int { return abs(int(l)) - 1 }
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+type clause struct {
+	lits   []literal
+	reason string
+}
+
+type problem struct {
+	vars    []*debcontrol.BinaryPackage
+	index   map[*debcontrol.BinaryPackage]int
+	clauses []clause
+}
+
+func (pr *problem) pos(p *debcontrol.BinaryPackage) literal { return literal(pr.index[p] + 1) }
+func (pr *problem) neg(p *debcontrol.BinaryPackage) literal { return -literal(pr.index[p] + 1) }
+
+// buildProblem translates the request into clauses over the packages that are reachable from the goals via
+// dependencies.  Packages that are not reachable can never usefully be installed, so they are left out.  No package
+// matched by conflicts may be installed.
+func (u *Universe) buildProblem(goals debrelation.Relations, fromArch string,
+	conflicts debrelation.Relations) *problem {
+
+	pr := &problem{index: make(map[*debcontrol.BinaryPackage]int)}
+
+	var queue []*debcontrol.BinaryPackage
+	visit := func(ps []*debcontrol.BinaryPackage) {
+		for _, p := range ps {
+			if _, ok := pr.index[p]; !ok {
+				pr.index[p] = len(pr.vars)
+				pr.vars = append(pr.vars, p)
+				queue = append(queue, p)
+			}
+		}
+	}
+	for _, alts := range goals {
+		visit(u.WhatProvidesAny(alts, fromArch))
+	}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		for _, alts := range dependencies(p) {
+			visit(u.WhatProvidesAny(alts, p.Architecture))
+		}
+	}
+
+	for _, alts := range goals {
+		c := clause{reason: fmt.Sprintf("%v is requested", alts)}
+		for _, q := range u.WhatProvidesAny(alts, fromArch) {
+			c.lits = append(c.lits, pr.pos(q))
+		}
+		if len(c.lits) == 0 {
+			c.reason += ", but no package satisfies it"
+		}
+		pr.clauses = append(pr.clauses, c)
+	}
+	for _, alts := range conflicts {
+		for _, r := range alts {
+			for _, q := range u.WhatConflicts(r) {
+				if _, ok := pr.index[q]; ok {
+					pr.clauses = append(pr.clauses, clause{
+						lits:   []literal{pr.neg(q)},
+						reason: fmt.Sprintf("%v is forbidden (%v)", r, q),
+					})
+				}
+			}
+		}
+	}
+
+	for _, p := range pr.vars {
+		for _, alts := range dependencies(p) {
+			c := clause{lits: []literal{pr.neg(p)}, reason: fmt.Sprintf("%v depends on %v", p, alts)}
+			for _, q := range u.WhatProvidesAny(alts, p.Architecture) {
+				c.lits = append(c.lits, pr.pos(q))
+			}
+			if len(c.lits) == 1 {
+				c.reason += ", but no package satisfies it"
+			}
+			pr.clauses = append(pr.clauses, c)
+		}
+
+		for _, f := range []struct {
+			verb string
+			rs   debrelation.Relations
+		}{
+			{"conflicts with", p.Conflicts},
+			{"breaks", p.Breaks},
+		} {
+			for _, alts := range f.rs {
+				for _, r := range alts {
+					for _, q := range u.WhatConflicts(r) {
+						// A package never conflicts with itself, including its instances for other architectures.
+						if _, ok := pr.index[q]; !ok || q.Package == p.Package {
+							continue
+						}
+						pr.clauses = append(pr.clauses, clause{
+							lits:   []literal{pr.neg(p), pr.neg(q)},
+							reason: fmt.Sprintf("%v %s %v (%v)", p, f.verb, r, q),
+						})
+					}
+				}
+			}
+		}
+	}
+
+	// At most one package with any given name may be installed, except for "Multi-Arch: same" packages.
+	for i, p := range pr.vars {
+		for _, q := range pr.vars[i+1:] {
+			if !u.CoInstallable(p, q) {
+				pr.clauses = append(pr.clauses, clause{
+					lits:   []literal{pr.neg(p), pr.neg(q)},
+					reason: fmt.Sprintf("%v and %v cannot both be installed", p, q),
+				})
+			}
+		}
+	}
+
+	return pr
+}
+
+func dependencies(p *debcontrol.BinaryPackage) debrelation.Relations {
+	deps := make(debrelation.Relations, 0, len(p.PreDepends)+len(p.Depends))
+	deps = append(deps, p.PreDepends...)
+	return append(deps, p.Depends...)
+}
+
+// explain finds a minimal unsatisfiable subset of the problem's clauses by deletion: each clause is dropped in turn,
+// and stays dropped if the remainder is still unsatisfiable.  The solver calls share a budget of maxExplainWork; if it
+// runs out, the clauses that have not been tried yet are all kept, and minimal is false.
+func (pr *problem) explain() (reasons []string, minimal bool) {
+	core := append([]clause(nil), pr.clauses...)
+	budget := maxExplainWork
+	minimal = true
+	for i := 0; i < len(core); {
+		if budget <= 0 {
+			minimal = false
+			break
+		}
+		trial := append(append([]clause(nil), core[:i]...), core[i+1:]...)
+		s := newSolver(trial, len(pr.vars))
+		s.maxWork = budget
+		ok, err := s.solve()
+		budget -= s.work
+		if err == nil && !ok {
+			core = trial
+		} else {
+			i++
+		}
+	}
+
+	reasons = make([]string, len(core))
+	for i, c := range core {
+		reasons[i] = c.reason
+	}
+	return reasons, minimal
+}
+
+type value int8
+
+const (
+	valUnassigned value = iota
+	valTrue
+	valFalse
+)
+
+// solver is a straightforward DPLL satisfiability solver.  Variables that are never assigned are taken to be false (not
+// installed), and its decision heuristic (see choose) is what gives the resolver its preferences.
+type solver struct {
+	clauses   []clause
+	assign    []value
+	trail     []int
+	decisions int
+	// work counts the clauses examined by propagate.  If maxWork is not zero, the search gives up once work exceeds it.
+	work    int
+	maxWork int
+}
+
+func newSolver(clauses []clause, nvars int) *solver {
+	return &solver{clauses: clauses, assign: make([]value, nvars)}
+}
+
+func (s *solver) valueOf(l literal) value {
+	v := s.assign[l.variable()]
+	if v == valUnassigned || l > 0 {
+		return v
+	}
+	if v == valTrue {
+		return valFalse
+	}
+	return valTrue
+}
+
+func (s *solver) set(l literal) {
+	if l > 0 {
+		s.assign[l.variable()] = valTrue
+	} else {
+		s.assign[l.variable()] = valFalse
+	}
+	s.trail = append(s.trail, l.variable())
+}
+
+func (s *solver) undo(mark int) {
+	for _, v := range s.trail[mark:] {
+		s.assign[v] = valUnassigned
+	}
+	s.trail = s.trail[:mark]
+}
+
+// propagate repeatedly assigns the last remaining literal of otherwise-false clauses.  It returns false if some clause
+// has become entirely false.
+func (s *solver) propagate() bool {
+	for changed := true; changed; {
+		changed = false
+		s.work += len(s.clauses)
+		for _, c := range s.clauses {
+			var unassigned literal
+			n, satisfied := 0, false
+			for _, l := range c.lits {
+				switch s.valueOf(l) {
+				case valTrue:
+					satisfied = true
+				case valUnassigned:
+					unassigned = l
+					n++
+				}
+				if satisfied {
+					break
+				}
+			}
+			switch {
+			case satisfied:
+			case n == 0:
+				return false
+			case n == 1:
+				s.set(unassigned)
+				changed = true
+			}
+		}
+	}
+	return true
+}
+
+// choose picks the next literal to try, or returns 0 if the clauses are all satisfied once every unassigned variable
+// is taken to be false.  Only clauses that would be violated by that default need a decision, and those are given one
+// by making their first unassigned positive literal true.
+func (s *solver) choose() literal {
+	for _, c := range s.clauses {
+		var firstPos literal
+		needed := true
+		for _, l := range c.lits {
+			v := s.valueOf(l)
+			if v == valTrue || (v == valUnassigned && l < 0) {
+				needed = false
+				break
+			}
+			if v == valUnassigned && firstPos == 0 {
+				firstPos = l
+			}
+		}
+		if needed && firstPos != 0 {
+			return firstPos
+		}
+	}
+	return 0
+}
+
+func (s *solver) solve() (bool, error) {
+	mark := len(s.trail)
+	if !s.propagate() {
+		s.undo(mark)
+		return false, nil
+	}
+	if s.maxWork != 0 && s.work > s.maxWork {
+		return false, ErrSearchLimit
+	}
+
+	l := s.choose()
+	if l == 0 {
+		return true, nil
+	}
+
+	for _, try := range []literal{l, -l} {
+		if s.decisions++; s.decisions > maxDecisions {
+			return false, ErrSearchLimit
+		}
+		inner := len(s.trail)
+		s.set(try)
+		ok, err := s.solve()
+		if err != nil || ok {
+			return ok, err
+		}
+		s.undo(inner)
+	}
+
+	s.undo(mark)
+	return false, nil
+}
@@ -0,0 +1,215 @@
+package debresolve
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kelleyk/godebian/debcontrol"
+	"github.com/kelleyk/godebian/debrelation"
+	"github.com/stretchr/testify/assert"
+)
+
+func testUniverse(t *testing.T, s string) *Universe {
+	ps, err := debcontrol.ParseParagraphs(strings.NewReader(s))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pkgs []*debcontrol.BinaryPackage
+	for _, p := range ps {
+		if p.Value("Architecture") == "" {
+			p.Set("Architecture", "amd64")
+		}
+		b, err := debcontrol.ParseBinaryPackage(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pkgs = append(pkgs, b)
+	}
+	return NewUniverse(pkgs)
+}
+
+func mustParse(t *testing.T, s string) debrelation.Relations {
+	rs, err := debrelation.Parse(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rs
+}
+
+func solutionNames(sol *Solution) []string {
+	var names []string
+	for _, p := range sol.Packages {
+		names = append(names, p.Package+"="+p.Version.String())
+	}
+	return names
+}
+
+const testPackages = `
+Package: app
+Version: 1.0
+Depends: libfoo (>= 2.0), mail-transport-agent, editor | vim
+
+Package: libfoo
+Version: 1.5
+
+Package: libfoo
+Version: 2.1
+Pre-Depends: libc6
+
+Package: libc6
+Version: 2.36-9
+
+Package: postfix
+Version: 3.7
+Provides: mail-transport-agent
+Conflicts: mail-transport-agent
+
+Package: exim4
+Version: 4.96
+Provides: mail-transport-agent
+Conflicts: mail-transport-agent
+
+Package: vim
+Version: 9.0
+Provides: editor (= 9.0)
+
+Package: nano
+Version: 7.2
+Provides: editor
+
+Package: old-app
+Version: 1.0
+Depends: libfoo (<< 2.0), app
+
+Package: needs-new-editor
+Version: 1.0
+Depends: editor (>= 8)
+
+Package: broken
+Version: 1.0
+Depends: does-not-exist
+`
+
+func TestWhatProvides(t *testing.T) {
+	u := testUniverse(t, testPackages)
+
+	names := func(ps []*debcontrol.BinaryPackage) []string {
+		var ss []string
+		for _, p := range ps {
+			ss = append(ss, p.Package+"="+p.Version.String())
+		}
+		return ss
+	}
+	assert.Equal(t, []string{"libfoo=2.1", "libfoo=1.5"}, names(u.Lookup("libfoo")))
+	assert.Equal(t, []string{"libfoo=2.1"}, names(u.WhatProvides(mustParse(t, "libfoo (>= 2)")[0][0], "")))
+	assert.Equal(t, []string{"exim4=4.96", "postfix=3.7"},
+		names(u.WhatProvides(mustParse(t, "mail-transport-agent")[0][0], "")))
+	assert.True(t, u.IsVirtual("mail-transport-agent"))
+	assert.False(t, u.IsVirtual("libfoo"))
+
+	// Only versioned Provides satisfy versioned relations.
+	assert.Equal(t, []string{"vim=9.0"}, names(u.WhatProvides(mustParse(t, "editor (>= 8)")[0][0], "")))
+	assert.Equal(t, []string{"nano=7.2", "vim=9.0"}, names(u.WhatProvides(mustParse(t, "editor")[0][0], "")))
+}
+
+func TestResolve(t *testing.T) {
+	u := testUniverse(t, testPackages)
+
+	sol, err := u.Resolve(mustParse(t, "app"))
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"app=1.0", "exim4=4.96", "libc6=2.36-9", "libfoo=2.1", "nano=7.2"}, solutionNames(sol))
+	}
+
+	sol, err = u.Resolve(mustParse(t, "app, postfix, vim"))
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"app=1.0", "libc6=2.36-9", "libfoo=2.1", "postfix=3.7", "vim=9.0"},
+			solutionNames(sol))
+	}
+
+	sol, err = u.Resolve(mustParse(t, "libfoo (<< 2)"))
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"libfoo=1.5"}, solutionNames(sol))
+	}
+
+	sol, err = u.Resolve(nil)
+	if assert.NoError(t, err) {
+		assert.Len(t, sol.Packages, 0)
+	}
+}
+
+func TestResolveUnsatisfiable(t *testing.T) {
+	u := testUniverse(t, testPackages)
+
+	_, err := u.Resolve(mustParse(t, "old-app"))
+	if assert.IsType(t, &UnsatisfiableError{}, err) {
+		reasons := err.(*UnsatisfiableError).Reasons
+		sort.Strings(reasons)
+		assert.Equal(t, []string{
+			"app_1.0_amd64 depends on libfoo (>= 2.0)",
+			"libfoo_1.5_amd64 and libfoo_2.1_amd64 cannot both be installed",
+			"old-app is requested",
+			"old-app_1.0_amd64 depends on app",
+			"old-app_1.0_amd64 depends on libfoo (<< 2.0)",
+		}, reasons)
+	}
+
+	_, err = u.Resolve(mustParse(t, "postfix, exim4"))
+	if assert.IsType(t, &UnsatisfiableError{}, err) {
+		reasons := err.(*UnsatisfiableError).Reasons
+		assert.Len(t, reasons, 3)
+		assert.Contains(t, reasons, "postfix is requested")
+		assert.Contains(t, reasons, "exim4 is requested")
+	}
+
+	_, err = u.Resolve(mustParse(t, "broken | nonexistent"))
+	if assert.IsType(t, &UnsatisfiableError{}, err) {
+		assert.Equal(t, []string{
+			"broken | nonexistent is requested",
+			"broken_1.0_amd64 depends on does-not-exist, but no package satisfies it",
+		}, err.(*UnsatisfiableError).Reasons)
+	}
+
+	_, err = u.Resolve(mustParse(t, "nonexistent"))
+	if assert.IsType(t, &UnsatisfiableError{}, err) {
+		assert.Equal(t, []string{"nonexistent is requested, but no package satisfies it"},
+			err.(*UnsatisfiableError).Reasons)
+		assert.Contains(t, err.Error(), "unsatisfiable dependencies")
+		assert.False(t, err.(*UnsatisfiableError).Incomplete)
+	}
+
+	sol, err := u.Resolve(mustParse(t, "needs-new-editor"))
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"needs-new-editor=1.0", "vim=9.0"}, solutionNames(sol))
+	}
+}
+
+// TestResolveExplainBudget checks that explaining a large unsatisfiable request gives up on minimizing the explanation
+// rather than taking (effectively) forever: without a budget, this takes minutes.
+func TestResolveExplainBudget(t *testing.T) {
+	// A long chain of dependencies, each of which has several candidates, that ends in a dependency on a missing
+	// package.
+	const n = 400
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		for v := 1; v <= 3; v++ {
+			fmt.Fprintf(&b, "Package: p%d\nVersion: %d\nDepends: p%d | q%d\n\n", i, v, i+1, i)
+		}
+		fmt.Fprintf(&b, "Package: q%d\nVersion: 1\nDepends: p%d\n\n", i, i+1)
+	}
+	fmt.Fprintf(&b, "Package: p%d\nVersion: 1\nDepends: missing\n\n", n)
+	u := testUniverse(t, b.String())
+
+	start := time.Now()
+	_, err := u.Resolve(mustParse(t, "p0"))
+	assert.True(t, time.Since(start) < 20*time.Second, "took %v", time.Since(start))
+	if assert.IsType(t, &UnsatisfiableError{}, err) {
+		assert.True(t, err.(*UnsatisfiableError).Incomplete)
+		assert.Contains(t, err.(*UnsatisfiableError).Reasons, "p0 is requested")
+		assert.Contains(t, err.(*UnsatisfiableError).Reasons,
+			fmt.Sprintf("p%d_1_amd64 depends on missing, but no package satisfies it", n))
+		assert.Contains(t, err.Error(), "may be irrelevant")
+	}
+}
@@ -0,0 +1,235 @@
+package debresolve
+
+import (
+	"sort"
+
+	"github.com/kelleyk/godebian/debcontrol"
+	"github.com/kelleyk/godebian/debrelation"
+	"github.com/kelleyk/godebian/debversion"
+)
+
+// provision records that a package provides a (possibly virtual) package name, optionally at a specific version.
+type provision struct {
+	pkg     *debcontrol.BinaryPackage
+	version *debversion.DebianVersion
+}
+
+type Options struct {
+	// NativeArch is the native architecture of the system that packages will be installed on (as printed by 'dpkg
+	// --print-architecture').  When it is set, relations are evaluated with Multi-Arch semantics; when it is empty,
+	// architectures are ignored entirely, which is only appropriate for single-architecture universes.
+	NativeArch string
+}
+
+// Universe is an index over a set of binary packages that answers questions about which packages satisfy a relation.
+type Universe struct {
+	opts      Options
+	packages  []*debcontrol.BinaryPackage
+	byName    map[string][]*debcontrol.BinaryPackage
+	providers map[string][]provision
+}
+
+// NewUniverse indexes the given packages, ignoring architectures.  Packages with the same name are ordered from the
+// highest version to the lowest, which is the order in which the resolver prefers them.
+func NewUniverse(pkgs []*debcontrol.BinaryPackage) *Universe {
+	return NewUniverseWithOptions(pkgs, Options{})
+}
+
+func NewUniverseWithOptions(pkgs []*debcontrol.BinaryPackage, opts Options) *Universe {
+	u := &Universe{
+		opts:      opts,
+		packages:  pkgs,
+		byName:    make(map[string][]*debcontrol.BinaryPackage),
+		providers: make(map[string][]provision),
+	}
+
+	for _, p := range pkgs {
+		u.byName[p.Package] = append(u.byName[p.Package], p)
+		for _, alts := range p.Provides {
+			for _, r := range alts {
+				pv := provision{pkg: p}
+				if r.Constraint != nil && r.Constraint.Op == debversion.OpEqual {
+					v := r.Constraint.Version
+					pv.version = &v
+				}
+				u.providers[r.Name] = append(u.providers[r.Name], pv)
+			}
+		}
+	}
+	for _, ps := range u.byName {
+		sort.SliceStable(ps, func(i, j int) bool { return higherPriority(ps[i], ps[j], opts.NativeArch) })
+	}
+	for _, pvs := range u.providers {
+		sort.SliceStable(pvs, func(i, j int) bool {
+			if pvs[i].pkg.Package != pvs[j].pkg.Package {
+				return pvs[i].pkg.Package < pvs[j].pkg.Package
+			}
+			return higherPriority(pvs[i].pkg, pvs[j].pkg, opts.NativeArch)
+		})
+	}
+
+	return u
+}
+
+// higherPriority orders packages with the same name: higher versions first and, among packages with the same version,
+// the native architecture first.
+func higherPriority(a, b *debcontrol.BinaryPackage, nativeArch string) bool {
+	switch a.Version.Compare(b.Version) {
+	case debversion.ResultGreater:
+		return true
+	case debversion.ResultLess:
+		return false
+	}
+	return a.Architecture == nativeArch && b.Architecture != nativeArch
+}
+
+// Packages returns every package in the universe.
+func (u *Universe) Packages() []*debcontrol.BinaryPackage {
+	return u.packages
+}
+
+// NativeArch returns the native architecture that the universe was created with.
+func (u *Universe) NativeArch() string {
+	return u.opts.NativeArch
+}
+
+// Lookup returns the packages with the given name, from the highest version to the lowest.
+func (u *Universe) Lookup(name string) []*debcontrol.BinaryPackage {
+	return u.byName[name]
+}
+
+// IsVirtual reports whether name is provided by some package but is not the name of any real package.
+func (u *Universe) IsVirtual(name string) bool {
+	return len(u.byName[name]) == 0 && len(u.providers[name]) > 0
+}
+
+// Providers returns the packages that provide name via their Provides field.
+func (u *Universe) Providers(name string) []*debcontrol.BinaryPackage {
+	var ps []*debcontrol.BinaryPackage
+	for _, pv := range u.providers[name] {
+		ps = append(ps, pv.pkg)
+	}
+	return ps
+}
+
+// effectiveArch maps "all" (and the empty string, which is used for requests that do not come from any package) to
+// the native architecture, since that is what Multi-Arch treats them as.
+func (u *Universe) effectiveArch(arch string) string {
+	if arch == "" || arch == "all" {
+		return u.opts.NativeArch
+	}
+	return arch
+}
+
+// archSatisfies reports whether candidate c, which has the right name and version, satisfies a relation with the given
+// architecture qualifier from a package of architecture fromArch.  This follows deparchsatisfied() in dpkg: an
+// unqualified relation implicitly refers to the architecture of the package it comes from, and only that implicit
+// qualifier is relaxed by Multi-Arch: foreign.  Architecture-independent packages count as native.
+//
+// Ref.: https://wiki.ubuntu.com/MultiarchSpec and the "Multi-Arch" section of deb-control(5).
+func (u *Universe) archSatisfies(c *debcontrol.BinaryPackage, qualifier, fromArch string) bool {
+	if u.opts.NativeArch == "" {
+		return true
+	}
+	arch := u.effectiveArch(c.Architecture)
+	switch qualifier {
+	case "":
+		return arch == u.effectiveArch(fromArch) || c.MultiArch == debcontrol.MultiArchForeign
+	case "any":
+		return c.MultiArch == debcontrol.MultiArchAllowed
+	case "native":
+		return arch == u.opts.NativeArch
+	default:
+		return arch == qualifier
+	}
+}
+
+// candidates returns the packages that match r by name and version, ignoring architecture: real packages first, then
+// providers.  As required by Policy, a versioned relation is only satisfied by a provider if the Provides entry is
+// itself versioned (with '=') and that version satisfies the relation.
+func (u *Universe) candidates(r debrelation.Relation) []*debcontrol.BinaryPackage {
+	var ps []*debcontrol.BinaryPackage
+	seen := make(map[*debcontrol.BinaryPackage]bool)
+
+	for _, p := range u.byName[r.Name] {
+		if r.Constraint == nil || r.Constraint.Satisfies(p.Version) {
+			ps = append(ps, p)
+			seen[p] = true
+		}
+	}
+	for _, pv := range u.providers[r.Name] {
+		if seen[pv.pkg] {
+			continue
+		}
+		if r.Constraint != nil && (pv.version == nil || !r.Constraint.Satisfies(*pv.version)) {
+			continue
+		}
+		ps = append(ps, pv.pkg)
+		seen[pv.pkg] = true
+	}
+
+	return ps
+}
+
+// WhatProvides returns the packages that satisfy r when it appears in a dependency field of a package of architecture
+// fromArch.  Use the empty string for fromArch when the relation does not come from a package (e.g. a request to
+// install something), which makes unqualified names refer to the native architecture.
+func (u *Universe) WhatProvides(r debrelation.Relation, fromArch string) []*debcontrol.BinaryPackage {
+	var ps []*debcontrol.BinaryPackage
+	for _, c := range u.candidates(r) {
+		if u.archSatisfies(c, r.ArchQualifier, fromArch) {
+			ps = append(ps, c)
+		}
+	}
+	return ps
+}
+
+// WhatProvidesAny returns the packages that satisfy any one of the alternatives, without duplicates.
+func (u *Universe) WhatProvidesAny(alts debrelation.Alternatives, fromArch string) []*debcontrol.BinaryPackage {
+	var ps []*debcontrol.BinaryPackage
+	seen := make(map[*debcontrol.BinaryPackage]bool)
+	for _, r := range alts {
+		for _, p := range u.WhatProvides(r, fromArch) {
+			if !seen[p] {
+				ps = append(ps, p)
+				seen[p] = true
+			}
+		}
+	}
+	return ps
+}
+
+// WhatConflicts returns the packages matched by r when it appears in a Conflicts or Breaks field.  Unlike
+// dependencies, these apply to packages of every architecture unless r names a specific one.
+func (u *Universe) WhatConflicts(r debrelation.Relation) []*debcontrol.BinaryPackage {
+	var ps []*debcontrol.BinaryPackage
+	for _, c := range u.candidates(r) {
+		switch r.ArchQualifier {
+		case "", "any":
+		case "native":
+			if u.opts.NativeArch != "" && u.effectiveArch(c.Architecture) != u.opts.NativeArch {
+				continue
+			}
+		default:
+			if u.opts.NativeArch != "" && u.effectiveArch(c.Architecture) != r.ArchQualifier {
+				continue
+			}
+		}
+		ps = append(ps, c)
+	}
+	return ps
+}
+
+// CoInstallable reports whether two packages with the same name may be installed at the same time: this is only
+// possible for "Multi-Arch: same" packages of different architectures with identical versions.  Packages with
+// different names are always co-installable as far as this method is concerned.
+func (u *Universe) CoInstallable(a, b *debcontrol.BinaryPackage) bool {
+	if a.Package != b.Package {
+		return true
+	}
+	if a == b || u.opts.NativeArch == "" {
+		return a == b
+	}
+	return a.MultiArch == debcontrol.MultiArchSame && b.MultiArch == debcontrol.MultiArchSame &&
+		u.effectiveArch(a.Architecture) != u.effectiveArch(b.Architecture) && a.Version.Equal(b.Version)
+}
@@ -0,0 +1,74 @@
+package debversion
+
+import (
+	"strings"
+)
+
+// Canonical returns an equivalent version in its conventional form: a zero epoch and a zero Debian revision are
+// omitted, and leading zeros are removed from each run of digits.  For example, the canonical form of "0:01.002-0" is
+// "1.2".
+//
+// A missing digit run at the end of a part compares equal to zero, so "1." and "1.0" are the same version, as are
+// "1.0~" and "1.0~0".  Canonical writes that final zero only after a '.', which gives "1.0" and "1.0~".  Two versions
+// therefore have the same canonical form exactly when they compare equal.
+func (v DebianVersion) Canonical() DebianVersion {
+	c := DebianVersion{
+		Epoch:           canonicalFragment(v.Epoch),
+		UpstreamVersion: canonicalFragment(v.UpstreamVersion),
+		DebianRevision:  canonicalFragment(v.DebianRevision),
+	}
+	if c.Epoch == "0" {
+		c.Epoch = ""
+	}
+	if c.DebianRevision == "0" {
+		c.DebianRevision = ""
+	}
+	return c
+}
+
+// Key returns a string that identifies the version for use as a map key or in a database uniqueness constraint.  Two
+// versions have the same key exactly when they compare equal.
+//
+// The key is always of the form "epoch:upstream-revision", and is itself a valid version when v is.  Unlike the
+// canonical form, it keeps a zero epoch and revision and appends a "0" to any part that does not end in a digit.
+func (v DebianVersion) Key() string {
+	return fragmentKey(v.Epoch) + ":" + fragmentKey(v.UpstreamVersion) + "-" + fragmentKey(v.DebianRevision)
+}
+
+// stripLeadingZeros removes the leading zeros from each run of digits in s, leaving at least one digit in each run.
+func stripLeadingZeros(s string) string {
+	var b strings.Builder
+	for s != "" {
+		var np, dp string
+		np, s = partitionNonDigit(s)
+		dp, s = partitionDigit(s)
+		b.WriteString(np)
+		if dp != "" {
+			if dp = strings.TrimLeft(dp, "0"); dp == "" {
+				dp = "0"
+			}
+			b.WriteString(dp)
+		}
+	}
+	return b.String()
+}
+
+// fragmentKey returns the form of a single part of a version (as compared by doCmpFragment) in which every non-digit
+// run is followed by a digit run without leading zeros.  Two parts compare equal exactly when their keys are the same.
+func fragmentKey(s string) string {
+	s = stripLeadingZeros(s)
+	if s == "" || !isDigit(s[len(s)-1]) {
+		s += "0"
+	}
+	return s
+}
+
+// canonicalFragment returns the form of a single part of a version that Canonical uses: its key, without a final zero
+// digit run unless that follows a '.'.  Keys always end in a digit, so this maps distinct keys to distinct strings.
+func canonicalFragment(s string) string {
+	s = fragmentKey(s)
+	if n := len(s); n > 1 && s[n-1] == '0' && !isDigit(s[n-2]) && s[n-2] != '.' {
+		s = s[:n-1]
+	}
+	return s
+}
@@ -0,0 +1,93 @@
+package debversion
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanonical(t *testing.T) {
+	for _, tt := range []struct {
+		v, canonical, key string
+	}{
+		{"1.0", "1.0", "0:1.0-0"},
+		{"0:1.0-0", "1.0", "0:1.0-0"},
+		{"00:01.002-00", "1.2", "0:1.2-0"},
+		{"1:1.0-1", "1:1.0-1", "1:1.0-1"},
+		{"007:1.0~rc01-0ubuntu01", "7:1.0~rc1-0ubuntu1", "7:1.0~rc1-0ubuntu1"},
+		{"1.", "1.0", "0:1.0-0"},
+		{"1.00", "1.0", "0:1.0-0"},
+		{"0.9.8a-1", "0.9.8a-1", "0:0.9.8a0-1"},
+		{"0.9.8a0-1", "0.9.8a-1", "0:0.9.8a0-1"},
+		{"2.0~0", "2.0~", "0:2.0~0-0"},
+		{"1.0+dfsg00-1", "1.0+dfsg-1", "0:1.0+dfsg0-1"},
+		{"1.0-1.", "1.0-1.0", "0:1.0-1.0"},
+		{"1.0-a0", "1.0-a", "0:1.0-a0"},
+	} {
+		v := mustVersion(t, tt.v)
+		assert.Equal(t, tt.canonical, v.Canonical().String(), tt.v)
+		assert.Equal(t, tt.key, v.Key(), tt.v)
+		assert.True(t, v.Equal(v.Canonical()), tt.v)
+		assert.Equal(t, v.Canonical(), v.Canonical().Canonical(), tt.v)
+		assert.True(t, v.Equal(mustVersion(t, v.Key())), tt.v)
+	}
+}
+
+func TestKeyConsistentWithEqual(t *testing.T) {
+	versions := []string{
+		"1", "1.", "1.0", "1.00", "01.0", "0:1.0", "1.0-0", "1.0-00", "1.0-0.", "1.0.", "1.0.0", "1.0~", "1.0~0",
+		"1:1.0", "01:1.0", "1.0-1", "1.0-01", "1.0-1.0", "1.0-1.", "a", "a0", "a00", "", "0", "~", "~0", "1.0a",
+		"1.0a0", "1.0+", "1.0+0", "2:", "2:0",
+	}
+	for _, a := range versions {
+		for _, b := range versions {
+			av, bv := FromStringLenient(a), FromStringLenient(b)
+			assert.Equal(t, av.Equal(bv), av.Key() == bv.Key(), "%q (key %q) <> %q (key %q)", a, av.Key(), b, bv.Key())
+			assert.Equal(t, av.Equal(bv), av.Canonical() == bv.Canonical(), "%q (canonical %q) <> %q (canonical %q)",
+				a, av.Canonical(), b, bv.Canonical())
+		}
+	}
+}
+
+// TestCanonicalConsistentWithEqual checks that Canonical(a) == Canonical(b) exactly when a and b compare equal, for
+// random versions built from pieces that make equal versions likely.
+func TestCanonicalConsistentWithEqual(t *testing.T) {
+	pieces := []string{"0", "00", "1", "01", "10", ".", "~", "+", "a", "b0"}
+	part := func(r *rand.Rand) string {
+		s := ""
+		for i := r.Intn(4); i >= 0; i-- {
+			s += pieces[r.Intn(len(pieces))]
+		}
+		return s
+	}
+
+	r := rand.New(rand.NewSource(1))
+	var versions []DebianVersion
+	for i := 0; i < 1000; i++ {
+		v := FromStringLenient("1" + part(r))
+		if r.Intn(3) == 0 {
+			v.Epoch = []string{"0", "00", "1", "01"}[r.Intn(4)]
+		}
+		if r.Intn(2) == 0 {
+			v.DebianRevision = part(r)
+		}
+		versions = append(versions, v)
+	}
+
+	equal := 0
+	for _, a := range versions {
+		for _, b := range versions {
+			eq := a.Compare(b) == ResultEqual
+			if eq != (a.Canonical() == b.Canonical()) {
+				t.Fatalf("%q <> %q: Compare gives %v but the canonical forms are %q and %q", a, b, a.Compare(b),
+					a.Canonical(), b.Canonical())
+			}
+			if eq {
+				equal++
+			}
+		}
+	}
+	// Make sure that the test exercises more than the trivial case of comparing each version with itself.
+	assert.True(t, equal > 2*len(versions), "only %d equal pairs", equal)
+}
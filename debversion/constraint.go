@@ -0,0 +1,93 @@
+package debversion
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Constraint is a version restriction such as the ">= 2.34" in "libc6 (>= 2.34)".
+type Constraint struct {
+	Op      Operator
+	Version DebianVersion
+}
+
+// ParseConstraint parses a constraint with or without its surrounding parentheses, e.g. "(>= 2.34)" or ">=2.34".
+func ParseConstraint(s string) (Constraint, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "(") {
+		if !strings.HasSuffix(s, ")") {
+			return Constraint{}, errors.Errorf("unbalanced parentheses in version constraint: %q", s)
+		}
+		s = strings.TrimSpace(s[1 : len(s)-1])
+	}
+
+	i := strings.IndexFunc(s, func(r rune) bool { return !strings.ContainsRune("<=>", r) })
+	if i <= 0 {
+		return Constraint{}, errors.Errorf("missing operator in version constraint: %q", s)
+	}
+	op, err := ParseOperator(s[:i])
+	if err != nil {
+		return Constraint{}, err
+	}
+
+	vs := strings.TrimSpace(s[i:])
+	if vs == "" {
+		return Constraint{}, errors.Errorf("missing version in version constraint: %q", s)
+	}
+	v, err := FromString(vs)
+	if err != nil {
+		return Constraint{}, errors.Wrap(err, "failed to parse version in version constraint")
+	}
+
+	return Constraint{Op: op, Version: v}, nil
+}
+
+func (c Constraint) String() string {
+	return fmt.Sprintf("%s %s", c.Op, c.Version)
+}
+
+// Satisfies reports whether v satisfies the constraint.
+func (c Constraint) Satisfies(v DebianVersion) bool {
+	r := v.Compare(c.Version)
+	switch c.Op.Normalize() {
+	case OpLess:
+		return r == ResultLess
+	case OpLessEqual:
+		return r == ResultLess || r == ResultEqual
+	case OpEqual:
+		return r == ResultEqual
+	case OpGreaterEqual:
+		return r == ResultGreater || r == ResultEqual
+	case OpGreater:
+		return r == ResultGreater
+	default:
+		return false
+	}
+}
+
+// Range returns the range of versions that satisfy the constraint.
+func (c Constraint) Range() Range {
+	b := &Bound{Version: c.Version}
+	switch c.Op.Normalize() {
+	case OpLess:
+		return Range{Upper: b}
+	case OpLessEqual:
+		b.Inclusive = true
+		return Range{Upper: b}
+	case OpEqual:
+		// Each end gets its own Bound, so that callers can adjust one without affecting the other.
+		return Range{
+			Lower: &Bound{Version: c.Version, Inclusive: true},
+			Upper: &Bound{Version: c.Version, Inclusive: true},
+		}
+	case OpGreaterEqual:
+		b.Inclusive = true
+		return Range{Lower: b}
+	case OpGreater:
+		return Range{Lower: b}
+	default:
+		return emptyRange()
+	}
+}
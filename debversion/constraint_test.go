@@ -0,0 +1,123 @@
+package debversion
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func mustConstraint(t *testing.T, s string) Constraint {
+	c, err := ParseConstraint(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func mustVersion(t *testing.T, s string) DebianVersion {
+	v, err := FromString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestParseConstraint(t *testing.T) {
+	c, err := ParseConstraint("(>= 1:2.0-1)")
+	if assert.NoError(t, err) {
+		assert.Equal(t, OpGreaterEqual, c.Op)
+		assert.Equal(t, "1:2.0-1", c.Version.String())
+		assert.Equal(t, ">= 1:2.0-1", c.String())
+	}
+
+	c, err = ParseConstraint("<1.0")
+	if assert.NoError(t, err) {
+		assert.Equal(t, OpLessDeprecated, c.Op)
+		assert.Equal(t, OpLessEqual, c.Op.Normalize())
+		assert.Equal(t, "< 1.0", c.String())
+	}
+
+	for _, s := range []string{"", "(>= 1.0", ">= ", "1.0", "=> 1.0", "~ 1.0"} {
+		_, err := ParseConstraint(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestSatisfies(t *testing.T) {
+	for _, tt := range []struct {
+		constraint string
+		version    string
+		expected   bool
+	}{
+		{"<< 2.0", "1.9", true},
+		{"<< 2.0", "2.0", false},
+		{"<< 2.0", "2.0~rc1", true},
+		{"<= 2.0", "2.0-0", true},
+		{"<= 2.0", "2.0-1", false},
+		{"= 1.0", "0:1.0", true},
+		{"= 1.0", "1.0+b1", false},
+		{">= 1.2", "1.2", true},
+		{">= 1.2", "1.10", true},
+		{">> 1.2", "1.2", false},
+		{">> 1.2", "1.2.1", true},
+
+		// The deprecated operators are not strict.
+		{"< 2.0", "2.0", true},
+		{"< 2.0", "2.1", false},
+		{"> 2.0", "2.0", true},
+		{"> 2.0", "1.9", false},
+	} {
+		c := mustConstraint(t, tt.constraint)
+		assert.Equal(t, tt.expected, c.Satisfies(mustVersion(t, tt.version)),
+			"%q satisfies %q", tt.version, tt.constraint)
+	}
+}
+
+func TestRange(t *testing.T) {
+	r := IntersectConstraints(mustConstraint(t, ">= 1.2"), mustConstraint(t, "<< 2.0~"))
+	assert.Equal(t, ">= 1.2, << 2.0~", r.String())
+	assert.False(t, r.IsEmpty())
+	for v, expected := range map[string]bool{
+		"1.1": false, "1.2": true, "1.99": true, "2.0~rc1": false, "2.0~": false, "2.0": false,
+	} {
+		assert.Equal(t, expected, r.Contains(mustVersion(t, v)), v)
+	}
+
+	assert.True(t, IntersectConstraints(mustConstraint(t, ">> 2.0"), mustConstraint(t, "<< 1.0")).IsEmpty())
+	assert.True(t, IntersectConstraints(mustConstraint(t, ">> 1.0"), mustConstraint(t, "<= 1.0")).IsEmpty())
+	assert.Equal(t, "= 1.0", IntersectConstraints(mustConstraint(t, ">= 1.0"), mustConstraint(t, "<= 1.0")).String())
+	assert.Equal(t, ">> 1.0, << 2.0", IntersectConstraints(
+		mustConstraint(t, ">= 1.0"), mustConstraint(t, ">> 1.0"), mustConstraint(t, "<< 2.0")).String())
+	assert.Equal(t, "*", Range{}.String())
+
+	// The two ends of a range never share a Bound, so changing one leaves the other alone.
+	for _, r := range []Range{mustConstraint(t, "= 1.0").Range(), emptyRange()} {
+		r.Lower.Inclusive = false
+		r.Lower.Version = mustVersion(t, "0.5")
+		assert.NotEqual(t, r.Lower, r.Upper)
+	}
+	r = mustConstraint(t, "= 1.0").Range()
+	r.Upper.Inclusive = false
+	assert.True(t, r.Lower.Inclusive)
+}
+
+func TestUnion(t *testing.T) {
+	str := func(rs []Range) []string {
+		var ss []string
+		for _, r := range rs {
+			ss = append(ss, r.String())
+		}
+		return ss
+	}
+	c := func(s string) Range { return mustConstraint(t, s).Range() }
+
+	assert.Equal(t, []string{"<< 1.0", ">> 2.0"}, str(Union(c(">> 2.0"), c("<< 1.0"))))
+	assert.Equal(t, []string{"*"}, str(Union(c(">= 1.0"), c("<< 1.0"))))
+	assert.Equal(t, []string{"<< 1.0", ">> 1.0"}, str(Union(c(">> 1.0"), c("<< 1.0"))))
+	assert.Equal(t, []string{"<= 2.0"}, str(Union(c("<= 2.0"), c("<< 1.0"))))
+	assert.Equal(t, []string{">= 1.0, <= 3.0"}, str(Union(
+		IntersectConstraints(mustConstraint(t, ">= 1.0"), mustConstraint(t, "<< 2.0")),
+		IntersectConstraints(mustConstraint(t, ">= 2.0"), mustConstraint(t, "<= 3.0")))))
+	assert.Equal(t, []string{"= 1.0"}, str(Union(c("= 1.0"), emptyRange())))
+	assert.Nil(t, Union(emptyRange(), emptyRange()))
+}
@@ -3,6 +3,7 @@ package debversion
 import (
 	"fmt"
 	"strconv"
+	"strings"
 	"unicode"
 
 	"github.com/kelleyk/gokk"
@@ -20,7 +21,82 @@ type DebianVersion struct {
 	DebianRevision  string
 }
 
+// SyntaxError describes why a version string was rejected by FromString.  The reasons match the messages printed by
+// dpkg.
+type SyntaxError struct {
+	Version string
+	Reason  string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("version %q has bad syntax: %s", e.Version, e.Reason)
+}
+
+// maxEpoch is the largest epoch that dpkg accepts (INT_MAX).
+const maxEpoch = 1<<31 - 1
+
+// FromString parses and validates a version string.  Leading and trailing whitespace is ignored, as it is by dpkg.
+//
+// Validation follows Policy and dpkg's parseversion(): the epoch, if present, must be a non-negative integer; the
+// upstream version must be nonempty, start with a digit and contain only alphanumerics and the characters ".+-~:"
+// (a hyphen can only appear when there is a Debian revision, since the last hyphen starts the revision, and a colon
+// only when there is an epoch, since the first colon ends it); and the Debian revision, if present, must be nonempty
+// and contain only alphanumerics and the characters ".+~".  Unlike Policy, and like dpkg, colons are tolerated in the
+// upstream version.
+//
+// Use FromStringLenient to accept versions that do not meet these rules.
 func FromString(s string) (DebianVersion, error) {
+	trimmed := strings.TrimSpace(s)
+	fail := func(reason string) (DebianVersion, error) {
+		return DebianVersion{}, &SyntaxError{Version: s, Reason: reason}
+	}
+
+	switch {
+	case trimmed == "":
+		return fail("version string is empty")
+	case strings.IndexFunc(trimmed, unicode.IsSpace) != -1:
+		return fail("version string has embedded spaces")
+	}
+
+	v := FromStringLenient(trimmed)
+	if strings.Contains(trimmed, ":") {
+		switch {
+		case v.Epoch == "":
+			return fail("epoch in version is empty")
+		case !allBytes(v.Epoch, isDigit):
+			return fail("epoch in version is not number")
+		case len(strings.TrimLeft(v.Epoch, "0")) > 10:
+			return fail("epoch in version is too big")
+		}
+		if epoch, err := strconv.ParseInt(v.Epoch, 10, 64); err != nil || epoch > maxEpoch {
+			return fail("epoch in version is too big")
+		}
+		if v.UpstreamVersion == "" && v.DebianRevision == "" {
+			return fail("nothing after colon in version number")
+		}
+	}
+	if strings.HasSuffix(trimmed, "-") {
+		return fail("revision number is empty")
+	}
+
+	switch {
+	case v.UpstreamVersion == "":
+		return fail("version number is empty")
+	case !isDigit(v.UpstreamVersion[0]):
+		return fail("version number does not start with digit")
+	case !allBytes(v.UpstreamVersion, func(c byte) bool { return isAlnum(c) || strings.IndexByte(".+-~:", c) != -1 }):
+		return fail("invalid character in version number")
+	case !allBytes(v.DebianRevision, func(c byte) bool { return isAlnum(c) || strings.IndexByte(".+~", c) != -1 }):
+		return fail("invalid character in revision number")
+	}
+
+	return v, nil
+}
+
+// FromStringLenient splits a version string into its parts without validating it, and so never fails.  This is useful
+// for tolerating the malformed versions that turn up in real-world package metadata; Compare is well-defined (and does
+// not panic) for any DebianVersion, however it was created.
+func FromStringLenient(s string) DebianVersion {
 	epoch, _, s := gokk.Partition(s, ":")
 	upstream, _, debian := gokk.PartitionLast(s, "-")
 	// N.B.: We deliberately do not normalize things here.
@@ -28,7 +104,16 @@ func FromString(s string) (DebianVersion, error) {
 		Epoch:           epoch,
 		UpstreamVersion: upstream,
 		DebianRevision:  debian,
-	}, nil
+	}
+}
+
+func allBytes(s string, f func(byte) bool) bool {
+	for i := 0; i < len(s); i++ {
+		if !f(s[i]) {
+			return false
+		}
+	}
+	return true
 }
 
 func (v DebianVersion) String() string {
@@ -90,20 +175,12 @@ func doCmpVersion(a, b DebianVersion) Result {
 	//
 	// @KK: E="epoch", V="upstream version", R="debian version"
 
-	switch r := doCmpFragment(a.normEpoch(), b.normEpoch()); r {
-	case ResultEqual:
-	case ResultLess, ResultGreater:
+	if r := doCmpFragment(a.normEpoch(), b.normEpoch()); r != ResultEqual {
 		return r
-	default:
-		panic(fmt.Sprintf("unexpected result: %v", r))
 	}
 
-	switch r := doCmpFragment(a.normUpstreamVersion(), b.normUpstreamVersion()); r {
-	case ResultEqual:
-	case ResultLess, ResultGreater:
+	if r := doCmpFragment(a.normUpstreamVersion(), b.normUpstreamVersion()); r != ResultEqual {
 		return r
-	default:
-		panic(fmt.Sprintf("unexpected result: %v", r))
 	}
 
 	return doCmpFragment(a.normDebianRevision(), b.normDebianRevision())
@@ -148,85 +225,46 @@ func doCmpVersion(a, b DebianVersion) Result {
 //   result of the comparison. For these purposes an empty string (which can only occur at the end of one or both
 //   version strings being compared) counts as zero.
 //
+// The comparison works directly on bytes: it does not allocate, and digit runs of any length are compared without being
+// parsed as integers.
 func doCmpFragment(a, b string) Result {
-	// fmt.Printf("**** doCmpFragment: %q <> %q\n", a, b)
-
-	for {
+	for a != "" || b != "" {
 		var ap, bp string
 
-		// XXX: review this block
-		if a == "" && b == "" {
-			// fmt.Printf("  - break (0)\n")
-			// return ResultEqual <-- this is what'll eventually be returned
-			break
-		}
-
 		// Non-digit phase.
-		// fmt.Printf("  - entering non-digit phase; before split: a=%q b=%q\n", a, b)
 		ap, a = partitionNonDigit(a)
 		bp, b = partitionNonDigit(b)
-		// fmt.Printf("    - part: ap=%q bp=%q\n", ap, bp)
-		// fmt.Printf("    - rem.:  a=%q  b=%q\n", a, b)
-		switch r := doCmpNonDigits(ap, bp); r {
-		case ResultEqual:
-		default:
+		if r := doCmpNonDigits(ap, bp); r != ResultEqual {
 			return r
 		}
 
-		// // XXX: review this block
-		if a == "" && b == "" {
-			// fmt.Printf("  - break (1)\n")
-			// return ResultEqual <-- this is what'll eventually be returned
-			break
-		}
-
 		// Digit phase.
-		// fmt.Printf("  - entering digit phase; before split: a=%q b=%q\n", a, b)
 		ap, a = partitionDigit(a)
 		bp, b = partitionDigit(b)
-		// fmt.Printf("    - part: ap=%q bp=%q\n", ap, bp)
-		// fmt.Printf("    - rem.:  a=%q  b=%q\n", a, b)
-		switch {
-		case ap == "" && bp == "":
-			panic("path should be unreachable (0)")
-			// 	if a == "" || b == "" {
-			// 		panic(fmt.Sprintf("digit bad (B): %q <> %q", a, b))
-			// 	}
-			// 	// fmt.Printf("  - ret-equal (2)\n")
-			// 	return ResultEqual // XXX:
-		case ap == "":
-			return ResultLess
-		case bp == "":
-			return ResultGreater
-		}
-		// fmt.Printf("  - cmpDigit parts %q <> %q\n", ap, bp)
-		apInt, err := strconv.Atoi(ap)
-		if err != nil {
-			panic(err)
-		}
-		bpInt, err := strconv.Atoi(bp)
-		if err != nil {
-			panic(err)
-		}
-		switch {
-		case apInt > bpInt:
-			return ResultGreater
-		case apInt < bpInt:
-			return ResultLess
+		if r := doCmpDigits(ap, bp); r != ResultEqual {
+			return r
 		}
 	}
 
-	// XXX: this block should become unnecessary
-	// fmt.Printf("  - doCmpFragment end block (by len): %q <> %q\n", a, b)
+	return ResultEqual
+}
+
+// doCmpDigits compares two runs of ASCII digits numerically.  An empty run counts as zero, as it does in dpkg.  The runs
+// are compared as strings so that arbitrarily long numbers can neither overflow nor fail to parse.
+func doCmpDigits(ap, bp string) Result {
+	ap = strings.TrimLeft(ap, "0")
+	bp = strings.TrimLeft(bp, "0")
 	switch {
-	case len(a) == len(b):
-		return ResultEqual
+	case len(ap) > len(bp):
+		return ResultGreater
+	case len(ap) < len(bp):
+		return ResultLess
+	case ap > bp:
+		return ResultGreater
+	case ap < bp:
+		return ResultLess
 	default:
-		panic("path should be unreachable (1)")
-		// case len(a) > len(b):
-		// 	return ResultGreater // XXX: should this be greater or less?
-		// case len(a) < len(b):
-		// 	return ResultLess
+		return ResultEqual
 	}
 }
 
@@ -284,16 +322,18 @@ func isAlpha(c byte) bool {
 	}
 }
 
+func isAlnum(c byte) bool {
+	return isDigit(c) || isAlpha(c)
+}
+
+// order is only ever called on bytes from a non-digit part.  Bytes that cannot appear in a valid version (including
+// NUL, which in C marks the end of the string) are given the same order as other non-letters.
 func order(c byte) int {
 	switch {
-	case isDigit(c): // N.B.: in the C version, we would be seeing the first character of the next part
-		panic("should not be digits in a non-digit part")
 	case isAlpha(c):
 		return int(c) // ascii
 	case c == '~':
 		return -1
-	case c == '\x00': // XXX: in C, this is end-of-string
-		panic("should not be null characters in version strings")
 	default:
 		return int(c) + 256 // ascii
 	}
@@ -315,13 +355,19 @@ func (v DebianVersion) Equal(o DebianVersion) bool {
 	return doCmpVersion(v, o) == ResultEqual
 }
 
-// XXX: here we are using Unicode (runes); in the rest of this package, we use ASCII (bytes)
-//
-// If all of the runes in s are digits, should return (s, "").
+// If all of the bytes in s are ASCII digits, should return (s, "").
 func partitionDigit(s string) (string, string) {
-	return gokk.TakeWhile(s, unicode.IsDigit)
+	i := 0
+	for i < len(s) && isDigit(s[i]) {
+		i++
+	}
+	return s[:i], s[i:]
 }
 
 func partitionNonDigit(s string) (string, string) {
-	return gokk.TakeWhile(s, func(r rune) bool { return !unicode.IsDigit(r) })
+	i := 0
+	for i < len(s) && !isDigit(s[i]) {
+		i++
+	}
+	return s[:i], s[i:]
 }
@@ -2,6 +2,8 @@ package debversion
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -142,13 +144,125 @@ func TestCompareVersion(t *testing.T) {
 		}
 
 		avs, bvs := av.String(), bv.String()
-		assert.Equal(t, tt.a, avs, fmt.Sprintf("String() failed -- expected: %q, actual: %q", tt.a, avs))
-		assert.Equal(t, tt.b, bvs, fmt.Sprintf("String() failed -- expected: %q, actual: %q", tt.b, bvs))
+		// FromString ignores surrounding whitespace, as dpkg does.
+		ea, eb := strings.TrimSpace(tt.a), strings.TrimSpace(tt.b)
+		assert.Equal(t, ea, avs, fmt.Sprintf("String() failed -- expected: %q, actual: %q", ea, avs))
+		assert.Equal(t, eb, bvs, fmt.Sprintf("String() failed -- expected: %q, actual: %q", eb, bvs))
 	}
 }
 
+func TestFromStringValidation(t *testing.T) {
+	for _, tt := range []struct {
+		s      string
+		reason string // empty if the version is valid
+	}{
+		{"1.0-1", ""},
+		{" 9:1.18.37:4.3-22", ""},
+		{"7:1-a:b-5", ""},
+		{"57:1.2.3abYZ+~-4-5", ""},
+		{"0:0-0-0", ""},
+		{"2147483647:1", ""},
+		{"", "version string is empty"},
+		{"  ", "version string is empty"},
+		{"1.0 b", "version string has embedded spaces"},
+		{"1.0:1", "epoch in version is not number"},
+		{"1-a:b", "epoch in version is not number"},
+		{":1", "epoch in version is empty"},
+		{"1:", "nothing after colon in version number"},
+		{"2147483648:1", "epoch in version is too big"},
+		{"99999999999999999999:1", "epoch in version is too big"},
+		{"1-", "revision number is empty"},
+		{"-1", "version number is empty"},
+		{"a1", "version number does not start with digit"},
+		{"1_2", "invalid character in version number"},
+		{"1-a_b", "invalid character in revision number"},
+	} {
+		_, err := FromString(tt.s)
+		if tt.reason == "" {
+			assert.Nil(t, err, tt.s)
+			continue
+		}
+		if serr, ok := err.(*SyntaxError); assert.True(t, ok, "%q: expected *SyntaxError, got %v", tt.s, err) {
+			assert.Equal(t, tt.s, serr.Version)
+			assert.Equal(t, tt.reason, serr.Reason, tt.s)
+		}
+	}
+}
+
+func TestFromStringLenient(t *testing.T) {
+	v := FromStringLenient("a1:2_x-b_c")
+	assert.Equal(t, DebianVersion{Epoch: "a1", UpstreamVersion: "2_x", DebianRevision: "b_c"}, v)
+	assert.Equal(t, "a1:2_x-b_c", v.String())
+}
+
+func TestCompareDoesNotPanic(t *testing.T) {
+	versions := []string{
+		"", "1", "1.", "1.0", ":", "-", "x:1", "1:x", "a1", "1\x00", "1\x002", "~", "1_2",
+		"999999999999999999999999999999", "1.999999999999999999999999999999",
+		"1\u0663", "\u0663", "\xff\xfe", "0001.00", "1:1-\x00",
+	}
+	for _, a := range versions {
+		for _, b := range versions {
+			av, bv := FromStringLenient(a), FromStringLenient(b)
+			assert.NotPanics(t, func() {
+				reversed := map[Result]Result{ResultLess: ResultGreater, ResultGreater: ResultLess, ResultEqual: ResultEqual}
+				r, rr := av.Compare(bv), bv.Compare(av)
+				assert.Equal(t, reversed[r], rr, "%q <> %q is not antisymmetric", a, b)
+			})
+		}
+	}
+
+	a, b := FromStringLenient("1:20231015123456789012-1"), FromStringLenient("1:20231015123456789013~rc1")
+	assert.Equal(t, ResultLess, a.Compare(b))
+	assert.Equal(t, 0.0, testing.AllocsPerRun(100, func() { a.Compare(b) }))
+
+	assert.Equal(t, ResultEqual, FromStringLenient("1.").Compare(FromStringLenient("1.0")))
+	assert.Equal(t, ResultLess,
+		FromStringLenient("1.999999999999999999999").Compare(FromStringLenient("1.1000000000000000000000")))
+}
+
 func TestPartitionDigit(t *testing.T) {
 	p, r := partitionDigit("6")
 	assert.Equal(t, "6", p)
 	assert.Equal(t, "", r)
+
+	// Only ASCII digits count; U+0663 is ARABIC-INDIC DIGIT THREE.
+	p, r = partitionDigit("12\u0663")
+	assert.Equal(t, "12", p)
+	assert.Equal(t, "\u0663", r)
+	p, r = partitionNonDigit("\u0663a1")
+	assert.Equal(t, "\u0663a", p)
+	assert.Equal(t, "1", r)
+}
+
+var benchVersions = []string{
+	"1.0-1", "1:2.30.2-1+deb11u1", "2.36.1-8", "20231015123456789012", "1.2.3~rc1-2ubuntu0.1",
+	"5.10.0-26", "1:9.18.19-1~deb12u1", "0.9.8a+dfsg-3", "3.0.11-1~deb12u2", "2:8.2.3995-1+b1",
+	"7.88.1-10+deb12u4", "1.2.13.dfsg-1", "252.19-1~deb12u1", "4:22.12.3-1", "0.0~git20230101.abcdef-1",
+}
+
+func BenchmarkCompare(b *testing.B) {
+	vs := make([]DebianVersion, len(benchVersions))
+	for i, s := range benchVersions {
+		vs[i] = FromStringLenient(s)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		vs[i%len(vs)].Compare(vs[(i+1)%len(vs)])
+	}
+}
+
+func BenchmarkSort(b *testing.B) {
+	base := make([]DebianVersion, 0, 10000)
+	for i := 0; len(base) < cap(base); i++ {
+		base = append(base, FromStringLenient(fmt.Sprintf("%d:%s.%d", i%3, benchVersions[i%len(benchVersions)], i)))
+	}
+	vs := make([]DebianVersion, len(base))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		copy(vs, base)
+		sort.Slice(vs, func(i, j int) bool { return vs[i].LessThan(vs[j]) })
+	}
 }
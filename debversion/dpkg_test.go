@@ -0,0 +1,114 @@
+package debversion
+
+import (
+	"bufio"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var resultOps = map[string]Result{"<": ResultLess, "=": ResultEqual, ">": ResultGreater}
+
+// TestDpkgCompareCorpus checks Compare against the results that dpkg gives for the pairs of versions in
+// testdata/dpkg-compare.txt.
+func TestDpkgCompareCorpus(t *testing.T) {
+	f, err := os.Open("testdata/dpkg-compare.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	n := 0
+	scanner := bufio.NewScanner(f)
+	for lineno := 1; scanner.Scan(); lineno++ {
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			t.Fatalf("line %d: malformed case: %q", lineno, line)
+		}
+		expected, ok := resultOps[fields[1]]
+		if !ok {
+			t.Fatalf("line %d: unknown result %q", lineno, fields[1])
+		}
+
+		a, aerr := FromString(fields[0])
+		b, berr := FromString(fields[2])
+		if !assert.Nil(t, aerr, "line %d", lineno) || !assert.Nil(t, berr, "line %d", lineno) {
+			continue
+		}
+		assert.Equal(t, expected, a.Compare(b), "line %d: %s", lineno, line)
+		n++
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, n > 1000, "expected a large corpus; found %d cases", n)
+}
+
+func FuzzCompare(f *testing.F) {
+	for _, seed := range [][3]string{
+		{"1.0-1", "1.0-1", "1.0-1"},
+		{"1.0", "1.0~rc1", "1:0.9"},
+		{"1.", "1.0", "1.00"},
+		{"2.4.7-1", "2.4.7-z", "2.4.7-1+b1"},
+		{"0:1.0-0", "1.0~~", "20231015123456789012"},
+		{"1.0+dfsg-1ubuntu0.22.04.1", "1.0+dfsg-1~bpo11+1", "1.0+dfsg-1+deb12u1"},
+	} {
+		f.Add(seed[0], seed[1], seed[2])
+	}
+
+	f.Fuzz(func(t *testing.T, as, bs, cs string) {
+		// Compare must cope with anything that FromStringLenient produces.
+		la, lb := FromStringLenient(as), FromStringLenient(bs)
+		checkAntisymmetric(t, la, lb)
+
+		a, aerr := FromString(as)
+		b, berr := FromString(bs)
+		c, cerr := FromString(cs)
+		if aerr != nil || berr != nil || cerr != nil {
+			return
+		}
+
+		for _, v := range []DebianVersion{a, b, c} {
+			rv, err := FromString(v.String())
+			if err != nil {
+				t.Fatalf("%q does not round-trip: %v", v, err)
+			}
+			if rv != v {
+				t.Fatalf("%q round-trips to %#v", v, rv)
+			}
+			if !v.Equal(v) || !v.Equal(v.Canonical()) {
+				t.Fatalf("%q is not equal to itself or to its canonical form", v)
+			}
+		}
+
+		checkAntisymmetric(t, a, b)
+		if (a.Key() == b.Key()) != a.Equal(b) {
+			t.Fatalf("Key is inconsistent with Equal for %q and %q", a, b)
+		}
+		if (a.Canonical() == b.Canonical()) != a.Equal(b) {
+			t.Fatalf("Canonical is inconsistent with Equal for %q and %q", a, b)
+		}
+
+		// Transitivity: if a <= b and b <= c then a <= c, and likewise for equality.
+		ab, bc, ac := a.Compare(b), b.Compare(c), a.Compare(c)
+		if ab != ResultGreater && bc != ResultGreater && ac == ResultGreater {
+			t.Fatalf("%q <= %q <= %q but %q > %q", a, b, c, a, c)
+		}
+		if ab == ResultEqual && bc == ResultEqual && ac != ResultEqual {
+			t.Fatalf("%q = %q = %q but %q != %q", a, b, c, a, c)
+		}
+	})
+}
+
+func checkAntisymmetric(t *testing.T, a, b DebianVersion) {
+	reversed := map[Result]Result{ResultLess: ResultGreater, ResultGreater: ResultLess, ResultEqual: ResultEqual}
+	if r, rr := a.Compare(b), b.Compare(a); reversed[r] != rr {
+		t.Fatalf("comparison is not antisymmetric: %#v <> %#v is %s, but the reverse is %s", a, b, r, rr)
+	}
+}
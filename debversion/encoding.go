@@ -0,0 +1,85 @@
+package debversion
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+// Compare returns -1, 0 or +1 depending on whether a sorts before, the same as, or after b.  It has the signature
+// expected by slices.SortFunc and friends.
+func Compare(a, b DebianVersion) int {
+	switch doCmpVersion(a, b) {
+	case ResultLess:
+		return -1
+	case ResultGreater:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Versions is a list of versions that implements sort.Interface, sorting in ascending order.
+type Versions []DebianVersion
+
+func (vs Versions) Len() int           { return len(vs) }
+func (vs Versions) Less(i, j int) bool { return vs[i].LessThan(vs[j]) }
+func (vs Versions) Swap(i, j int)      { vs[i], vs[j] = vs[j], vs[i] }
+
+// MarshalText implements encoding.TextMarshaler.
+func (v DebianVersion) MarshalText() ([]byte, error) {
+	return []byte(v.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.  The version is validated as by FromString, except that empty
+// text, which is what the zero DebianVersion marshals to, yields the zero value.
+func (v *DebianVersion) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*v = DebianVersion{}
+		return nil
+	}
+	parsed, err := FromString(string(text))
+	if err != nil {
+		return err
+	}
+	*v = parsed
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler; a version is represented as a JSON string.
+func (v DebianVersion) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler.  As is conventional, a JSON null leaves the version unchanged.
+func (v *DebianVersion) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return errors.Wrap(err, "version must be a JSON string")
+	}
+	return v.UnmarshalText([]byte(s))
+}
+
+// Value implements driver.Valuer; a version is stored as a string.
+func (v DebianVersion) Value() (driver.Value, error) {
+	return v.String(), nil
+}
+
+// Scan implements sql.Scanner.  It accepts string and []byte values; use a pointer or sql.Null[DebianVersion] for
+// nullable columns.
+func (v *DebianVersion) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case string:
+		return v.UnmarshalText([]byte(src))
+	case []byte:
+		return v.UnmarshalText(src)
+	case nil:
+		return errors.New("cannot scan NULL into a DebianVersion")
+	default:
+		return errors.Errorf("cannot scan %T into a DebianVersion", src)
+	}
+}
@@ -0,0 +1,120 @@
+package debversion
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding"
+	"encoding/json"
+	"slices"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	_ encoding.TextMarshaler   = DebianVersion{}
+	_ encoding.TextUnmarshaler = (*DebianVersion)(nil)
+	_ json.Marshaler           = DebianVersion{}
+	_ json.Unmarshaler         = (*DebianVersion)(nil)
+	_ driver.Valuer            = DebianVersion{}
+	_ sql.Scanner              = (*DebianVersion)(nil)
+	_ sort.Interface           = Versions(nil)
+)
+
+func TestCompareFunc(t *testing.T) {
+	vs := []DebianVersion{
+		mustVersion(t, "1:0.1"), mustVersion(t, "1.0-1"), mustVersion(t, "1.0~rc1-1"), mustVersion(t, "1.0-1+b1"),
+	}
+	expected := []string{"1.0~rc1-1", "1.0-1", "1.0-1+b1", "1:0.1"}
+
+	sorted := slices.Clone(vs)
+	slices.SortFunc(sorted, Compare)
+	assert.Equal(t, expected, versionStrings(sorted))
+
+	sorted = slices.Clone(vs)
+	sort.Sort(Versions(sorted))
+	assert.Equal(t, expected, versionStrings(sorted))
+
+	assert.Equal(t, 0, Compare(mustVersion(t, "0:1.0"), mustVersion(t, "1.0-0")))
+}
+
+func TestJSON(t *testing.T) {
+	var doc struct {
+		Version DebianVersion  `json:"version"`
+		Old     *DebianVersion `json:"old"`
+	}
+	if !assert.Nil(t, json.Unmarshal([]byte(`{"version": "1:2.3-4", "old": null}`), &doc)) {
+		return
+	}
+	assert.Equal(t, DebianVersion{Epoch: "1", UpstreamVersion: "2.3", DebianRevision: "4"}, doc.Version)
+	assert.Nil(t, doc.Old)
+
+	data, err := json.Marshal(doc)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"version":"1:2.3-4","old":null}`, string(data))
+
+	// The zero value round-trips.
+	doc.Version = DebianVersion{}
+	data, err = json.Marshal(doc)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"version":"","old":null}`, string(data))
+	doc.Version = mustVersion(t, "1.0")
+	if assert.Nil(t, json.Unmarshal(data, &doc)) {
+		assert.Equal(t, DebianVersion{}, doc.Version)
+	}
+
+	assert.NotNil(t, json.Unmarshal([]byte(`{"version": "a:b"}`), &doc))
+	assert.NotNil(t, json.Unmarshal([]byte(`{"version": 12}`), &doc))
+}
+
+func TestText(t *testing.T) {
+	m := map[DebianVersion]int{}
+	if !assert.Nil(t, json.Unmarshal([]byte(`{"1.0-1": 1, "2:3": 2}`), &m)) {
+		return
+	}
+	assert.Equal(t, 1, m[mustVersion(t, "1.0-1")])
+	assert.Equal(t, 2, m[mustVersion(t, "2:3")])
+
+	var v DebianVersion
+	text, err := DebianVersion{}.MarshalText()
+	assert.Nil(t, err)
+	assert.Equal(t, "", string(text))
+	assert.Nil(t, v.UnmarshalText([]byte("1.0")))
+	if assert.Nil(t, v.UnmarshalText(text)) {
+		assert.Equal(t, DebianVersion{}, v)
+	}
+}
+
+func TestSQL(t *testing.T) {
+	var v DebianVersion
+	assert.Nil(t, v.Scan("1.0-1"))
+	assert.Equal(t, "1.0-1", v.String())
+	assert.Nil(t, v.Scan([]byte("2:1.0")))
+	assert.Equal(t, "2:1.0", v.String())
+	assert.NotNil(t, v.Scan(nil))
+	assert.NotNil(t, v.Scan(42))
+	assert.NotNil(t, v.Scan("1.0 1"))
+
+	val, err := v.Value()
+	assert.Nil(t, err)
+	assert.Equal(t, "2:1.0", val)
+
+	val, err = DebianVersion{}.Value()
+	assert.Nil(t, err)
+	if assert.Nil(t, v.Scan(val)) {
+		assert.Equal(t, DebianVersion{}, v)
+	}
+
+	var nv sql.Null[DebianVersion]
+	assert.Nil(t, nv.Scan(nil))
+	assert.False(t, nv.Valid)
+}
+
+func versionStrings(vs []DebianVersion) []string {
+	var ss []string
+	for _, v := range vs {
+		ss = append(ss, v.String())
+	}
+	return ss
+}
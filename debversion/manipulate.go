@@ -0,0 +1,102 @@
+package debversion
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// IncrementRevision returns v with its Debian revision bumped, as "dch -i" would do: the trailing run of digits is
+// incremented ("3ubuntu4" becomes "3ubuntu5"), or "1" is appended if the revision does not end in a digit.  It is an
+// error to call IncrementRevision on a native version, which has no revision.
+func IncrementRevision(v DebianVersion) (DebianVersion, error) {
+	if v.DebianRevision == "" {
+		return DebianVersion{}, errors.Errorf("version %q is native and has no Debian revision", v.String())
+	}
+	v.DebianRevision = incrementPart(v.DebianRevision)
+	return v, nil
+}
+
+// Next returns a version strictly greater than v.  The last part of v (the Debian revision, or the upstream version of a
+// native version) is incremented in the same way as by IncrementRevision, so "1.0-1" becomes "1.0-2" and "1.0"
+// becomes "1.1".
+func Next(v DebianVersion) DebianVersion {
+	if v.DebianRevision == "" {
+		v.UpstreamVersion = incrementPart(v.UpstreamVersion)
+	} else {
+		v.DebianRevision = incrementPart(v.DebianRevision)
+	}
+	return v
+}
+
+// LowerThanUpstream returns the version "X~", where X is the epoch and upstream version of v.  It sorts before every
+// version with that epoch and upstream version, including pre-releases such as "X~rc1"; only versions that use a double
+// tilde ("X~~") sort lower still.  This is the version conventionally used in relationships like "Breaks: foo (<< X~)".
+func LowerThanUpstream(v DebianVersion) DebianVersion {
+	return DebianVersion{Epoch: v.Epoch, UpstreamVersion: v.UpstreamVersion + "~"}
+}
+
+// AddSuffix appends a distribution suffix such as "~bpo12+1", "+deb12u1" or "ubuntu1" to the last part of v, and
+// validates the result.
+func AddSuffix(v DebianVersion, suffix string) (DebianVersion, error) {
+	if strings.ContainsAny(suffix, ":-") {
+		return DebianVersion{}, errors.Errorf("suffix %q must not contain ':' or '-'", suffix)
+	}
+	nv, err := FromString(v.String() + suffix)
+	if err != nil {
+		return DebianVersion{}, errors.Wrapf(err, "failed to add suffix %q", suffix)
+	}
+	return nv, nil
+}
+
+// StripSuffix removes suffix from the end of the last part of v.  It reports false, and returns v unchanged, if v does
+// not end with suffix.
+func StripSuffix(v DebianVersion, suffix string) (DebianVersion, bool) {
+	last := &v.UpstreamVersion
+	if v.DebianRevision != "" {
+		last = &v.DebianRevision
+	}
+	if suffix == "" || !strings.HasSuffix(*last, suffix) || len(*last) == len(suffix) {
+		return v, false
+	}
+	*last = strings.TrimSuffix(*last, suffix)
+	return v, true
+}
+
+// StripDistributionSuffix removes any trailing distribution suffixes (those that ClassifyOrigin recognizes) from v and
+// returns the stripped version together with the suffixes that were removed, which are empty if there were none.  A
+// revision that is entirely made up of an Ubuntu suffix, such as "0ubuntu1", is reduced to the Debian revision it was
+// derived from ("0").
+func StripDistributionSuffix(v DebianVersion) (DebianVersion, string) {
+	o := ClassifyOrigin(v)
+	var suffix strings.Builder
+	for _, s := range o.Suffixes {
+		suffix.WriteString(s.Text)
+	}
+	return o.Base, suffix.String()
+}
+
+// incrementPart increments the trailing run of digits in s, or appends "1" if s does not end in a digit.  Digits are
+// incremented as strings, so runs of any length are handled.
+func incrementPart(s string) string {
+	end := len(s)
+	start := end
+	for start > 0 && isDigit(s[start-1]) {
+		start--
+	}
+	if start == end {
+		return s + "1"
+	}
+
+	digits := []byte(s[start:end])
+	i := len(digits) - 1
+	for ; i >= 0 && digits[i] == '9'; i-- {
+		digits[i] = '0'
+	}
+	if i < 0 {
+		digits = append([]byte{'1'}, digits...)
+	} else {
+		digits[i]++
+	}
+	return s[:start] + string(digits)
+}
@@ -0,0 +1,108 @@
+package debversion
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIncrementRevision(t *testing.T) {
+	for _, tt := range []struct{ v, expected string }{
+		{"1.0-1", "1.0-2"},
+		{"1.0-3ubuntu4", "1.0-3ubuntu5"},
+		{"1.0-9", "1.0-10"},
+		{"1.0-1.99", "1.0-1.100"},
+		{"1.0-1+deb12u1", "1.0-1+deb12u2"},
+		{"1.0-1ubuntu", "1.0-1ubuntu1"},
+		{"1:2.0-99999999999999999999", "1:2.0-100000000000000000000"},
+	} {
+		v := mustVersion(t, tt.v)
+		nv, err := IncrementRevision(v)
+		if assert.Nil(t, err, tt.v) {
+			assert.Equal(t, tt.expected, nv.String())
+			assert.True(t, nv.GreaterThan(v), tt.v)
+		}
+	}
+
+	_, err := IncrementRevision(mustVersion(t, "1.0"))
+	assert.NotNil(t, err)
+}
+
+func TestNext(t *testing.T) {
+	for _, tt := range []struct{ v, expected string }{
+		{"1.0-1", "1.0-2"},
+		{"1.0", "1.1"},
+		{"2:1.9", "2:1.10"},
+		{"1.0~", "1.0~1"},
+		{"1.0-1~", "1.0-1~1"},
+		{"1.0+git", "1.0+git1"},
+	} {
+		v := FromStringLenient(tt.v)
+		nv := Next(v)
+		assert.Equal(t, tt.expected, nv.String())
+		assert.True(t, nv.GreaterThan(v), tt.v)
+	}
+}
+
+func TestLowerThanUpstream(t *testing.T) {
+	lower := LowerThanUpstream(mustVersion(t, "1:2.0-3"))
+	assert.Equal(t, "1:2.0~", lower.String())
+	for _, s := range []string{"1:2.0", "1:2.0-0", "1:2.0-1", "1:2.0~rc1-1", "1:2.0+dfsg-1"} {
+		assert.True(t, lower.LessThan(mustVersion(t, s)), s)
+	}
+	for _, s := range []string{"1:1.9-1", "2.0-1", "1:2.0~~-1", "1:2.0~~rc1-1"} {
+		assert.True(t, lower.GreaterThan(mustVersion(t, s)), s)
+	}
+}
+
+func TestSuffixes(t *testing.T) {
+	for _, tt := range []struct{ v, suffix, expected string }{
+		{"1.2-3", "~bpo12+1", "1.2-3~bpo12+1"},
+		{"1.2-3", "+deb12u1", "1.2-3+deb12u1"},
+		{"1.2-3", "ubuntu1", "1.2-3ubuntu1"},
+		{"1.2-3", "~22.04.1", "1.2-3~22.04.1"},
+		{"1.2-3", "+nmu1", "1.2-3+nmu1"},
+		{"1.2", "+nmu1", "1.2+nmu1"},
+	} {
+		v := mustVersion(t, tt.v)
+		nv, err := AddSuffix(v, tt.suffix)
+		if !assert.Nil(t, err, tt.v) {
+			continue
+		}
+		assert.Equal(t, tt.expected, nv.String())
+
+		sv, ok := StripSuffix(nv, tt.suffix)
+		assert.True(t, ok)
+		assert.True(t, sv.Equal(v), tt.v)
+
+		sv, suffix := StripDistributionSuffix(nv)
+		assert.Equal(t, tt.suffix, suffix, tt.expected)
+		assert.True(t, sv.Equal(v), tt.expected)
+	}
+
+	for _, suffix := range []string{"-1", "1:", "_x", " x"} {
+		_, err := AddSuffix(mustVersion(t, "1.0-1"), suffix)
+		assert.NotNil(t, err, suffix)
+	}
+
+	_, ok := StripSuffix(mustVersion(t, "1.0-1"), "+b1")
+	assert.False(t, ok)
+	_, ok = StripSuffix(mustVersion(t, "1.0-1"), "1")
+	assert.False(t, ok)
+
+	for _, tt := range []struct{ v, stripped, suffix string }{
+		{"1.2-0ubuntu1", "1.2-0", "ubuntu1"},
+		{"1.2-3ubuntu0.22.04.1", "1.2-3", "ubuntu0.22.04.1"},
+		{"1.2-3+deb11u2+b1", "1.2-3", "+deb11u2+b1"},
+		{"1.2-3~bpo11+1", "1.2-3", "~bpo11+1"},
+		{"252.19-1~deb12u1", "252.19-1", "~deb12u1"},
+		{"1.2-3build1", "1.2-3", "build1"},
+		{"1.2-3~ppa2", "1.2-3", "~ppa2"},
+		{"1.2-3", "1.2-3", ""},
+		{"1.2~rc1-3", "1.2~rc1-3", ""},
+	} {
+		sv, suffix := StripDistributionSuffix(mustVersion(t, tt.v))
+		assert.Equal(t, tt.stripped, sv.String(), tt.v)
+		assert.Equal(t, tt.suffix, suffix, tt.v)
+	}
+}
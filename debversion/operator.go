@@ -0,0 +1,62 @@
+package debversion
+
+import (
+	"github.com/pkg/errors"
+)
+
+// Operator is one of the version relation operators that may appear in a relationship field.
+//
+// Ref.: https://www.debian.org/doc/debian-policy/ch-relationships.html#syntax-of-relationship-fields
+type Operator int
+
+const (
+	OpUnknown Operator = iota
+	OpLess
+	OpLessEqual
+	OpEqual
+	OpGreaterEqual
+	OpGreater
+	// OpLessDeprecated ('<') and OpGreaterDeprecated ('>') are obsolete spellings that dpkg still accepts; despite
+	// their appearance, they mean "<=" and ">=" respectively.
+	OpLessDeprecated
+	OpGreaterDeprecated
+)
+
+var operatorStrings = map[Operator]string{
+	OpLess:         "<<",
+	OpLessEqual:    "<=",
+	OpEqual:        "=",
+	OpGreaterEqual: ">=",
+	OpGreater:      ">>",
+
+	OpLessDeprecated:    "<",
+	OpGreaterDeprecated: ">",
+}
+
+func (op Operator) String() string {
+	if s, ok := operatorStrings[op]; ok {
+		return s
+	}
+	return "INVALID"
+}
+
+// Normalize returns the non-deprecated operator with the same meaning as op.
+func (op Operator) Normalize() Operator {
+	switch op {
+	case OpLessDeprecated:
+		return OpLessEqual
+	case OpGreaterDeprecated:
+		return OpGreaterEqual
+	default:
+		return op
+	}
+}
+
+func ParseOperator(s string) (Operator, error) {
+	for op, opStr := range operatorStrings {
+		if s == opStr {
+			return op, nil
+		}
+	}
+	return OpUnknown, errors.Errorf("unknown version relation operator: %q", s)
+}
@@ -0,0 +1,129 @@
+package debversion
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// OSVEvent is one entry in the "events" list of an OSV range of type "ECOSYSTEM".  Exactly one field is set.
+//
+// Ref.: https://ossf.github.io/osv-schema/#affectedrangesevents-fields
+type OSVEvent struct {
+	Introduced   string `json:"introduced,omitempty"`
+	Fixed        string `json:"fixed,omitempty"`
+	LastAffected string `json:"last_affected,omitempty"`
+	Limit        string `json:"limit,omitempty"`
+}
+
+// osvUnbounded is the version that OSV uses in an "introduced" event to mean that every earlier version is affected.
+const osvUnbounded = "0"
+
+// OSVEvents returns the OSV events that describe the set.  A range that is unbounded below is introduced at "0".  OSV
+// has no way to express a lower bound that excludes its version, so such sets are an error.
+func (s RangeSet) OSVEvents() ([]OSVEvent, error) {
+	var events []OSVEvent
+	for _, r := range s.ranges {
+		switch {
+		case r.Lower == nil:
+			events = append(events, OSVEvent{Introduced: osvUnbounded})
+		case !r.Lower.Inclusive:
+			return nil, errors.Errorf("range %q has an exclusive lower bound, which OSV cannot express", r)
+		default:
+			events = append(events, OSVEvent{Introduced: r.Lower.Version.String()})
+		}
+
+		switch {
+		case r.Upper == nil:
+		case r.Upper.Inclusive:
+			events = append(events, OSVEvent{LastAffected: r.Upper.Version.String()})
+		default:
+			events = append(events, OSVEvent{Fixed: r.Upper.Version.String()})
+		}
+	}
+	return events, nil
+}
+
+// RangeSetFromOSVEvents returns the set of versions described by OSV events.  As the OSV schema requires, the events are
+// considered in version order (with an "introduced" event at "0" sorting first), and each "introduced" event starts an
+// affected range that the next "fixed" or "last_affected" event ends.  If there are "limit" events, only versions below
+// one of the limits are affected.
+func RangeSetFromOSVEvents(events []OSVEvent) (RangeSet, error) {
+	type event struct {
+		kind    string
+		version *DebianVersion // nil for an "introduced" event at "0"
+	}
+
+	var es []event
+	var limits []Range
+	for _, e := range events {
+		var kind, vs string
+		n := 0
+		for _, f := range []struct{ kind, vs string }{
+			{"introduced", e.Introduced}, {"fixed", e.Fixed}, {"last_affected", e.LastAffected}, {"limit", e.Limit},
+		} {
+			if f.vs != "" {
+				kind, vs = f.kind, f.vs
+				n++
+			}
+		}
+		if n != 1 {
+			return RangeSet{}, errors.Errorf("OSV event must have exactly one field set: %+v", e)
+		}
+
+		if kind == "introduced" && vs == osvUnbounded {
+			es = append(es, event{kind: kind})
+			continue
+		}
+		v, err := FromString(vs)
+		if err != nil {
+			return RangeSet{}, errors.Wrapf(err, "invalid version in OSV %q event", kind)
+		}
+		if kind == "limit" {
+			limits = append(limits, Range{Upper: &Bound{Version: v}})
+			continue
+		}
+		es = append(es, event{kind: kind, version: &v})
+	}
+
+	sort.SliceStable(es, func(i, j int) bool {
+		switch {
+		case es[j].version == nil:
+			return false
+		case es[i].version == nil:
+			return true
+		default:
+			return es[i].version.LessThan(*es[j].version)
+		}
+	})
+
+	var rs []Range
+	var lower *Bound
+	affected := false
+	for _, e := range es {
+		switch e.kind {
+		case "introduced":
+			if !affected {
+				affected = true
+				lower = nil
+				if e.version != nil {
+					lower = &Bound{Version: *e.version, Inclusive: true}
+				}
+			}
+		case "fixed", "last_affected":
+			if affected {
+				affected = false
+				rs = append(rs, Range{Lower: lower, Upper: &Bound{Version: *e.version, Inclusive: e.kind == "last_affected"}})
+			}
+		}
+	}
+	if affected {
+		rs = append(rs, Range{Lower: lower})
+	}
+
+	s := NewRangeSet(rs...)
+	if len(limits) != 0 {
+		s = s.Intersect(NewRangeSet(limits...))
+	}
+	return s, nil
+}
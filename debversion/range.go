@@ -0,0 +1,210 @@
+package debversion
+
+import (
+	"strings"
+)
+
+// Bound is one end of a Range.
+type Bound struct {
+	Version   DebianVersion
+	Inclusive bool
+}
+
+// Range is a contiguous range of versions.  A nil bound leaves that end of the range unbounded, so the zero value
+// contains every version.
+type Range struct {
+	Lower *Bound
+	Upper *Bound
+}
+
+func emptyRange() Range {
+	v := DebianVersion{UpstreamVersion: "0"}
+	return Range{Lower: &Bound{Version: v}, Upper: &Bound{Version: v}}
+}
+
+// Contains reports whether v lies inside of the range.
+func (r Range) Contains(v DebianVersion) bool {
+	if r.Lower != nil {
+		switch v.Compare(r.Lower.Version) {
+		case ResultLess:
+			return false
+		case ResultEqual:
+			if !r.Lower.Inclusive {
+				return false
+			}
+		}
+	}
+	if r.Upper != nil {
+		switch v.Compare(r.Upper.Version) {
+		case ResultGreater:
+			return false
+		case ResultEqual:
+			if !r.Upper.Inclusive {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// IsEmpty reports whether no version lies inside of the range.
+func (r Range) IsEmpty() bool {
+	if r.Lower == nil || r.Upper == nil {
+		return false
+	}
+	switch r.Lower.Version.Compare(r.Upper.Version) {
+	case ResultGreater:
+		return true
+	case ResultEqual:
+		return !(r.Lower.Inclusive && r.Upper.Inclusive)
+	default:
+		return false
+	}
+}
+
+// Intersect returns the range of versions that lie inside of both r and o.
+func (r Range) Intersect(o Range) Range {
+	return Range{
+		Lower: tighterBound(r.Lower, o.Lower, ResultGreater),
+		Upper: tighterBound(r.Upper, o.Upper, ResultLess),
+	}
+}
+
+// tighterBound picks the more restrictive of two bounds on the same end of a range; towards is ResultGreater when
+// comparing lower bounds and ResultLess when comparing upper bounds.
+func tighterBound(a, b *Bound, towards Result) *Bound {
+	switch {
+	case a == nil:
+		return b
+	case b == nil:
+		return a
+	}
+	switch a.Version.Compare(b.Version) {
+	case towards:
+		return a
+	case ResultEqual:
+		if !a.Inclusive {
+			return a
+		}
+		return b
+	default:
+		return b
+	}
+}
+
+// looserBound is the counterpart to tighterBound, picking the less restrictive of two bounds.
+func looserBound(a, b *Bound, towards Result) *Bound {
+	if a == nil || b == nil {
+		return nil
+	}
+	switch a.Version.Compare(b.Version) {
+	case towards:
+		return a
+	case ResultEqual:
+		if a.Inclusive {
+			return a
+		}
+		return b
+	default:
+		return b
+	}
+}
+
+// Union returns the versions that lie inside of either range, as a single range if they overlap or touch and as two
+// ranges (lower first) otherwise.  Empty ranges are omitted from the result.
+func Union(a, b Range) []Range {
+	switch {
+	case a.IsEmpty() && b.IsEmpty():
+		return nil
+	case a.IsEmpty():
+		return []Range{b}
+	case b.IsEmpty():
+		return []Range{a}
+	}
+
+	if startsBefore(b, a) {
+		a, b = b, a
+	}
+	// a starts no later than b; they can be merged unless there is a gap between the end of a and the start of b.
+	if a.Upper != nil && b.Lower != nil {
+		switch a.Upper.Version.Compare(b.Lower.Version) {
+		case ResultLess:
+			return []Range{a, b}
+		case ResultEqual:
+			if !a.Upper.Inclusive && !b.Lower.Inclusive {
+				return []Range{a, b}
+			}
+		}
+	}
+	return []Range{{Lower: a.Lower, Upper: looserBound(a.Upper, b.Upper, ResultGreater)}}
+}
+
+// startsBefore reports whether the lower end of a is strictly less restrictive than the lower end of b.
+func startsBefore(a, b Range) bool {
+	switch {
+	case b.Lower == nil:
+		return false
+	case a.Lower == nil:
+		return true
+	}
+	switch a.Lower.Version.Compare(b.Lower.Version) {
+	case ResultLess:
+		return true
+	case ResultEqual:
+		return a.Lower.Inclusive && !b.Lower.Inclusive
+	default:
+		return false
+	}
+}
+
+// IntersectConstraints returns the range of versions that satisfy all of the given constraints.
+func IntersectConstraints(cs ...Constraint) Range {
+	var r Range
+	for _, c := range cs {
+		r = r.Intersect(c.Range())
+	}
+	return r
+}
+
+// Constraints returns constraints that together describe the range: none for an unbounded range, one "=" constraint
+// for a single version, and otherwise up to one for each bound.  An empty range has no such description, so the result
+// for one is unspecified.
+func (r Range) Constraints() []Constraint {
+	var cs []Constraint
+	if r.Lower != nil && r.Upper != nil && r.Lower.Inclusive && r.Upper.Inclusive &&
+		r.Lower.Version.Equal(r.Upper.Version) {
+		return []Constraint{{Op: OpEqual, Version: r.Lower.Version}}
+	}
+	if r.Lower != nil {
+		op := OpGreater
+		if r.Lower.Inclusive {
+			op = OpGreaterEqual
+		}
+		cs = append(cs, Constraint{Op: op, Version: r.Lower.Version})
+	}
+	if r.Upper != nil {
+		op := OpLess
+		if r.Upper.Inclusive {
+			op = OpLessEqual
+		}
+		cs = append(cs, Constraint{Op: op, Version: r.Upper.Version})
+	}
+	return cs
+}
+
+// String formats the range as a comma-separated list of constraints (e.g. ">= 1.2, << 2.0~").  The unbounded range is
+// formatted as "*" and empty ranges as "<empty>".
+func (r Range) String() string {
+	if r.IsEmpty() {
+		return "<empty>"
+	}
+	cs := r.Constraints()
+	if len(cs) == 0 {
+		return "*"
+	}
+	parts := make([]string, len(cs))
+	for i, c := range cs {
+		parts[i] = c.String()
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,173 @@
+package debversion
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// RangeSet is a set of versions made up of any number of ranges.  It is kept normalized: its ranges are nonempty,
+// sorted, and neither overlap nor touch, so two sets that contain the same versions have the same ranges.  The zero
+// value is the empty set.
+type RangeSet struct {
+	ranges []Range
+}
+
+// NewRangeSet returns the set of versions that lie inside of any of the given ranges.
+func NewRangeSet(ranges ...Range) RangeSet {
+	var rs []Range
+	for _, r := range ranges {
+		if !r.IsEmpty() {
+			rs = append(rs, r)
+		}
+	}
+	sort.SliceStable(rs, func(i, j int) bool { return startsBefore(rs[i], rs[j]) })
+
+	var merged []Range
+	for _, r := range rs {
+		if len(merged) == 0 {
+			merged = append(merged, r)
+			continue
+		}
+		// Since r starts no earlier than the last range, the union is either a single range or the two ranges in order.
+		u := Union(merged[len(merged)-1], r)
+		merged = append(merged[:len(merged)-1], u...)
+	}
+	return RangeSet{ranges: merged}
+}
+
+// AllVersions returns the set that contains every version.
+func AllVersions() RangeSet {
+	return RangeSet{ranges: []Range{{}}}
+}
+
+// Ranges returns the normalized ranges that make up the set, in ascending order.
+func (s RangeSet) Ranges() []Range {
+	return append([]Range(nil), s.ranges...)
+}
+
+// IsEmpty reports whether no version lies inside of the set.
+func (s RangeSet) IsEmpty() bool {
+	return len(s.ranges) == 0
+}
+
+// Contains reports whether v lies inside of the set.
+func (s RangeSet) Contains(v DebianVersion) bool {
+	for _, r := range s.ranges {
+		if r.Contains(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// Includes reports whether every version in o also lies inside of s.
+func (s RangeSet) Includes(o RangeSet) bool {
+	return o.Intersect(s.Complement()).IsEmpty()
+}
+
+// Equal reports whether s and o contain exactly the same versions.
+func (s RangeSet) Equal(o RangeSet) bool {
+	return s.Includes(o) && o.Includes(s)
+}
+
+// Union returns the versions that lie inside of either set.
+func (s RangeSet) Union(o RangeSet) RangeSet {
+	return NewRangeSet(append(s.Ranges(), o.ranges...)...)
+}
+
+// Intersect returns the versions that lie inside of both sets.
+func (s RangeSet) Intersect(o RangeSet) RangeSet {
+	var rs []Range
+	for _, a := range s.ranges {
+		for _, b := range o.ranges {
+			rs = append(rs, a.Intersect(b))
+		}
+	}
+	return NewRangeSet(rs...)
+}
+
+// Complement returns the versions that do not lie inside of the set.
+func (s RangeSet) Complement() RangeSet {
+	if s.IsEmpty() {
+		return AllVersions()
+	}
+
+	var rs []Range
+	var lower *Bound // of the gap that ends at the start of the next range
+	for _, r := range s.ranges {
+		if r.Lower != nil {
+			rs = append(rs, Range{Lower: lower, Upper: &Bound{Version: r.Lower.Version, Inclusive: !r.Lower.Inclusive}})
+		}
+		if r.Upper == nil {
+			// Because the set is normalized, this can only be true of the last range.
+			return RangeSet{ranges: rs}
+		}
+		lower = &Bound{Version: r.Upper.Version, Inclusive: !r.Upper.Inclusive}
+	}
+	return RangeSet{ranges: append(rs, Range{Lower: lower})}
+}
+
+// String formats the set as its ranges (see Range.String) separated by " | ", e.g. ">= 1.0, << 1.4-2 | = 2.0".  The
+// empty set is formatted as "<empty>".
+func (s RangeSet) String() string {
+	if s.IsEmpty() {
+		return "<empty>"
+	}
+	parts := make([]string, len(s.ranges))
+	for i, r := range s.ranges {
+		parts[i] = r.String()
+	}
+	return strings.Join(parts, " | ")
+}
+
+// ParseRange parses a range in the format produced by Range.String: a comma-separated list of constraints, all of
+// which must be satisfied, or "*" for the unbounded range.
+func ParseRange(s string) (Range, error) {
+	s = strings.TrimSpace(s)
+	switch s {
+	case "*":
+		return Range{}, nil
+	case "<empty>":
+		return emptyRange(), nil
+	}
+
+	var cs []Constraint
+	for _, part := range strings.Split(s, ",") {
+		c, err := ParseConstraint(part)
+		if err != nil {
+			return Range{}, errors.Wrapf(err, "failed to parse range %q", s)
+		}
+		cs = append(cs, c)
+	}
+	return IntersectConstraints(cs...), nil
+}
+
+// ParseRangeSet parses a set in the format produced by RangeSet.String.  The ranges need not be normalized.
+func ParseRangeSet(s string) (RangeSet, error) {
+	var rs []Range
+	for _, part := range strings.Split(s, "|") {
+		r, err := ParseRange(part)
+		if err != nil {
+			return RangeSet{}, err
+		}
+		rs = append(rs, r)
+	}
+	return NewRangeSet(rs...), nil
+}
+
+// MarshalText implements encoding.TextMarshaler using the format produced by String.
+func (s RangeSet) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler using ParseRangeSet.
+func (s *RangeSet) UnmarshalText(text []byte) error {
+	parsed, err := ParseRangeSet(string(text))
+	if err != nil {
+		return err
+	}
+	*s = parsed
+	return nil
+}
@@ -0,0 +1,145 @@
+package debversion
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func mustRangeSet(t *testing.T, s string) RangeSet {
+	rs, err := ParseRangeSet(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rs
+}
+
+func TestParseRangeSet(t *testing.T) {
+	for _, tt := range []struct{ s, expected string }{
+		{">= 1.0, << 1.4-2 | >= 2.0~rc1, <= 2.1-1+deb12u1", ">= 1.0, << 1.4-2 | >= 2.0~rc1, <= 2.1-1+deb12u1"},
+		{">= 2.0~rc1, <= 2.1-1+deb12u1 | >= 1.0, << 1.4-2", ">= 1.0, << 1.4-2 | >= 2.0~rc1, <= 2.1-1+deb12u1"},
+		{">=1.0,<<2.0 | >= 1.5, << 3.0", ">= 1.0, << 3.0"},
+		{">= 1.0, << 2.0 | >= 2.0, << 3.0", ">= 1.0, << 3.0"},
+		{">= 1.0, << 2.0 | >> 2.0", ">= 1.0, << 2.0 | >> 2.0"},
+		{"= 1.0 | = 1.0", "= 1.0"},
+		{"*", "*"},
+		{"<< 1.0 | *", "*"},
+		{"<empty>", "<empty>"},
+		{">> 2.0, << 1.0", "<empty>"},
+	} {
+		assert.Equal(t, tt.expected, mustRangeSet(t, tt.s).String(), tt.s)
+	}
+
+	for _, s := range []string{"", ">= 1.0 |", "1.0", ">= a", "=> 1.0"} {
+		_, err := ParseRangeSet(s)
+		assert.NotNil(t, err, s)
+	}
+}
+
+func TestRangeSetAlgebra(t *testing.T) {
+	affected := mustRangeSet(t, ">= 1.0, << 1.4-2 | >= 2.0~rc1, <= 2.1-1+deb12u1")
+
+	for _, tt := range []struct {
+		v        string
+		expected bool
+	}{
+		{"0.9", false},
+		{"1.0", true},
+		{"1.4-1", true},
+		{"1.4-2", false},
+		{"2.0~beta1", false},
+		{"2.0~rc1", true},
+		{"2.1-1", true},
+		{"2.1-1+deb12u1", true},
+		{"2.1-1+deb12u2", false},
+	} {
+		assert.Equal(t, tt.expected, affected.Contains(mustVersion(t, tt.v)), tt.v)
+		assert.Equal(t, !tt.expected, affected.Complement().Contains(mustVersion(t, tt.v)), tt.v)
+	}
+
+	assert.Equal(t, "<< 1.0 | >= 1.4-2, << 2.0~rc1 | >> 2.1-1+deb12u1", affected.Complement().String())
+	assert.True(t, affected.Complement().Complement().Equal(affected))
+	assert.True(t, affected.Union(affected.Complement()).Equal(AllVersions()))
+	assert.True(t, affected.Intersect(affected.Complement()).IsEmpty())
+	assert.Equal(t, "<empty>", AllVersions().Complement().String())
+	assert.Equal(t, "*", RangeSet{}.Complement().String())
+	assert.Equal(t, "= 1.0", mustRangeSet(t, "<< 1.0 | >> 1.0").Complement().String())
+
+	fixedInStable := mustRangeSet(t, ">= 1.2, << 1.4-2")
+	assert.Equal(t, ">= 1.2, << 1.4-2", affected.Intersect(fixedInStable).String())
+	assert.True(t, affected.Includes(fixedInStable))
+	assert.False(t, fixedInStable.Includes(affected))
+	assert.Equal(t, ">= 1.0, << 1.4-2 | >= 2.0~rc1, <= 2.1-1+deb12u1 | >= 3.0",
+		affected.Union(mustRangeSet(t, ">= 3.0")).String())
+}
+
+func TestRangeSetText(t *testing.T) {
+	var doc struct {
+		Affected RangeSet `json:"affected"`
+	}
+	if !assert.Nil(t, json.Unmarshal([]byte(`{"affected": ">= 1.0, << 2.0 | >= 1.5"}`), &doc)) {
+		return
+	}
+	assert.Equal(t, ">= 1.0", doc.Affected.String())
+	data, err := json.Marshal(doc)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"affected":"\u003e= 1.0"}`, string(data)) // encoding/json escapes ">"
+}
+
+func TestOSVEvents(t *testing.T) {
+	affected := mustRangeSet(t, ">= 1.0, << 1.4-2 | >= 2.0~rc1, <= 2.1-1+deb12u1 | >= 3.0")
+	events, err := affected.OSVEvents()
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, []OSVEvent{
+		{Introduced: "1.0"}, {Fixed: "1.4-2"},
+		{Introduced: "2.0~rc1"}, {LastAffected: "2.1-1+deb12u1"},
+		{Introduced: "3.0"},
+	}, events)
+
+	data, err := json.Marshal(events[:2])
+	assert.Nil(t, err)
+	assert.Equal(t, `[{"introduced":"1.0"},{"fixed":"1.4-2"}]`, string(data))
+
+	rs, err := RangeSetFromOSVEvents(events)
+	if assert.Nil(t, err) {
+		assert.Equal(t, affected.String(), rs.String())
+	}
+
+	events, err = mustRangeSet(t, "<< 1.0-3").OSVEvents()
+	if assert.Nil(t, err) {
+		assert.Equal(t, []OSVEvent{{Introduced: "0"}, {Fixed: "1.0-3"}}, events)
+	}
+	_, err = mustRangeSet(t, ">> 1.0").OSVEvents()
+	assert.NotNil(t, err)
+}
+
+func TestRangeSetFromOSVEvents(t *testing.T) {
+	for _, tt := range []struct {
+		events   []OSVEvent
+		expected string
+	}{
+		// Events are sorted by version before being interpreted.
+		{[]OSVEvent{{Fixed: "2.0"}, {Introduced: "1.0"}}, ">= 1.0, << 2.0"},
+		{[]OSVEvent{{Introduced: "0"}}, "*"},
+		{[]OSVEvent{{Introduced: "0"}, {Fixed: "1.0"}, {Introduced: "1.5"}}, "<< 1.0 | >= 1.5"},
+		{[]OSVEvent{{Introduced: "1.0"}, {Limit: "1.5"}}, ">= 1.0, << 1.5"},
+		{nil, "<empty>"},
+	} {
+		rs, err := RangeSetFromOSVEvents(tt.events)
+		if assert.Nil(t, err) {
+			assert.Equal(t, tt.expected, rs.String())
+		}
+	}
+
+	for _, events := range [][]OSVEvent{
+		{{}},
+		{{Introduced: "1.0", Fixed: "2.0"}},
+		{{Introduced: "not a version"}},
+	} {
+		_, err := RangeSetFromOSVEvents(events)
+		assert.NotNil(t, err)
+	}
+}
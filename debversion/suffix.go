@@ -0,0 +1,151 @@
+package debversion
+
+import (
+	"regexp"
+	"strings"
+)
+
+// SuffixKind identifies one of the suffixes that distributions append to a version.
+type SuffixKind int
+
+const (
+	SuffixUnknown SuffixKind = iota
+	// SuffixUbuntu marks an Ubuntu delta, e.g. "ubuntu4" in "1.2-3ubuntu4" or "ubuntu0.22.04.1" in a stable release
+	// update.
+	SuffixUbuntu
+	// SuffixUbuntuRebuild marks an Ubuntu no-change rebuild, e.g. "build1".
+	SuffixUbuntuRebuild
+	// SuffixUbuntuRelease marks a build for a particular Ubuntu release, e.g. "~22.04.1".
+	SuffixUbuntuRelease
+	// SuffixStableUpdate marks an update to a Debian stable release, e.g. "+deb11u2"; security updates use this form,
+	// as do point-release updates.
+	SuffixStableUpdate
+	// SuffixBackport marks a Debian backport, e.g. "~bpo11+1".
+	SuffixBackport
+	// SuffixPPA marks a build in a Launchpad PPA, e.g. "~ppa1".
+	SuffixPPA
+	// SuffixNMU marks a non-maintainer upload of a native package, e.g. "+nmu1".
+	SuffixNMU
+	// SuffixBinNMU marks a binary-only non-maintainer upload, e.g. "+b1".
+	SuffixBinNMU
+)
+
+var suffixKindStrings = map[SuffixKind]string{
+	SuffixUbuntu:        "ubuntu",
+	SuffixUbuntuRebuild: "ubuntu-rebuild",
+	SuffixUbuntuRelease: "ubuntu-release",
+	SuffixStableUpdate:  "stable-update",
+	SuffixBackport:      "backport",
+	SuffixPPA:           "ppa",
+	SuffixNMU:           "nmu",
+	SuffixBinNMU:        "binnmu",
+}
+
+func (k SuffixKind) String() string {
+	if s, ok := suffixKindStrings[k]; ok {
+		return s
+	}
+	return "unknown"
+}
+
+// Suffix is a single distribution suffix found in a version.
+type Suffix struct {
+	Kind SuffixKind
+	// Text is the suffix exactly as it appears in the version, e.g. "+deb11u2".
+	Text string
+	// Release is the distribution release that the suffix names, if any: "11" for "+deb11u2" and "~bpo11+1", and
+	// "22.04" for "~22.04.1".
+	Release string
+	// Number is the counter carried by the suffix: "2" for "+deb11u2", "1" for "~bpo11+1" and "+b1", and "0.22.04.1"
+	// for "ubuntu0.22.04.1".
+	Number string
+}
+
+// Origin describes where a version came from, as far as that can be told from its distribution suffixes.
+type Origin struct {
+	// Suffixes lists the distribution suffixes in the order in which they appear in the version.
+	Suffixes []Suffix
+	// Base is the version with all of the suffixes removed.
+	Base DebianVersion
+	// NMU is true if the version is a (source) non-maintainer upload: either its base Debian revision has the form
+	// "3.1", or it carries a "+nmu" suffix.
+	NMU bool
+}
+
+// Has returns true if the version carries a suffix of the given kind.
+func (o Origin) Has(kind SuffixKind) bool {
+	return o.Suffix(kind) != nil
+}
+
+// Suffix returns the last suffix of the given kind, or nil if there is none.
+func (o Origin) Suffix(kind SuffixKind) *Suffix {
+	for i := len(o.Suffixes) - 1; i >= 0; i-- {
+		if o.Suffixes[i].Kind == kind {
+			return &o.Suffixes[i]
+		}
+	}
+	return nil
+}
+
+// IsUbuntu returns true if the version carries any suffix that Ubuntu (or a Launchpad PPA) uses.
+func (o Origin) IsUbuntu() bool {
+	return o.Has(SuffixUbuntu) || o.Has(SuffixUbuntuRebuild) || o.Has(SuffixUbuntuRelease) || o.Has(SuffixPPA)
+}
+
+// suffixPatterns are tried, in order, against the end of the last part of a version.  Each has two groups, which capture
+// the release and the number respectively (either may be empty).
+var suffixPatterns = []struct {
+	kind SuffixKind
+	re   *regexp.Regexp
+}{
+	{SuffixBinNMU, regexp.MustCompile(`\+b()([0-9]+)$`)},
+	{SuffixNMU, regexp.MustCompile(`\+nmu()([0-9]+)$`)},
+	{SuffixStableUpdate, regexp.MustCompile(`[+~]deb([0-9]+)u([0-9]+)$`)},
+	{SuffixBackport, regexp.MustCompile(`~bpo([0-9]+)\+([0-9]+)$`)},
+	{SuffixPPA, regexp.MustCompile(`~ppa()([0-9][0-9.]*)?$`)},
+	{SuffixUbuntuRelease, regexp.MustCompile(`~([0-9]+\.[0-9]+)(?:\.([0-9]+))?$`)},
+	{SuffixUbuntuRebuild, regexp.MustCompile(`build()([0-9]+)$`)},
+	{SuffixUbuntu, regexp.MustCompile(`ubuntu()([0-9][0-9.]*)$`)},
+}
+
+// ClassifyOrigin decomposes the distribution suffixes at the end of v (in its Debian revision, or in its upstream
+// version if it is native).
+func ClassifyOrigin(v DebianVersion) Origin {
+	last := v.UpstreamVersion
+	if v.DebianRevision != "" {
+		last = v.DebianRevision
+	}
+
+	var o Origin
+outer:
+	for {
+		for _, p := range suffixPatterns {
+			m := p.re.FindStringSubmatchIndex(last)
+			// Never strip a suffix that makes up the whole part; "1.0-ubuntu1" has no base revision to speak of.
+			if m == nil || m[0] == 0 {
+				continue
+			}
+			s := Suffix{Kind: p.kind, Text: last[m[0]:]}
+			if m[2] >= 0 {
+				s.Release = last[m[2]:m[3]]
+			}
+			if len(m) > 4 && m[4] >= 0 {
+				s.Number = last[m[4]:m[5]]
+			}
+			o.Suffixes = append([]Suffix{s}, o.Suffixes...)
+			last = last[:m[0]]
+			continue outer
+		}
+		break
+	}
+
+	o.Base = v
+	if v.DebianRevision != "" {
+		o.Base.DebianRevision = last
+		o.NMU = strings.Contains(last, ".")
+	} else {
+		o.Base.UpstreamVersion = last
+	}
+	o.NMU = o.NMU || o.Has(SuffixNMU)
+	return o
+}
@@ -0,0 +1,104 @@
+package debversion
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyOrigin(t *testing.T) {
+	for _, tt := range []struct {
+		v        string
+		base     string
+		nmu      bool
+		ubuntu   bool
+		suffixes []Suffix
+	}{
+		{"1.2-3", "1.2-3", false, false, nil},
+		{"1.2-3ubuntu4", "1.2-3", false, true, []Suffix{
+			{Kind: SuffixUbuntu, Text: "ubuntu4", Number: "4"},
+		}},
+		{"1.2-0ubuntu0.22.04.1", "1.2-0", false, true, []Suffix{
+			{Kind: SuffixUbuntu, Text: "ubuntu0.22.04.1", Number: "0.22.04.1"},
+		}},
+		{"1.2-3build1", "1.2-3", false, true, []Suffix{
+			{Kind: SuffixUbuntuRebuild, Text: "build1", Number: "1"},
+		}},
+		{"1.2-3+deb11u2", "1.2-3", false, false, []Suffix{
+			{Kind: SuffixStableUpdate, Text: "+deb11u2", Release: "11", Number: "2"},
+		}},
+		{"252.19-1~deb12u1", "252.19-1", false, false, []Suffix{
+			{Kind: SuffixStableUpdate, Text: "~deb12u1", Release: "12", Number: "1"},
+		}},
+		{"1.2-3~bpo11+1", "1.2-3", false, false, []Suffix{
+			{Kind: SuffixBackport, Text: "~bpo11+1", Release: "11", Number: "1"},
+		}},
+		{"1.2-3~ppa1", "1.2-3", false, true, []Suffix{
+			{Kind: SuffixPPA, Text: "~ppa1", Number: "1"},
+		}},
+		{"1.2-3.1", "1.2-3.1", true, false, nil},
+		{"1.2+nmu1", "1.2", true, false, []Suffix{
+			{Kind: SuffixNMU, Text: "+nmu1", Number: "1"},
+		}},
+		{"1.2-3+b1", "1.2-3", false, false, []Suffix{
+			{Kind: SuffixBinNMU, Text: "+b1", Number: "1"},
+		}},
+		{"1:1.2-3.1+deb11u2+b1", "1:1.2-3.1", true, false, []Suffix{
+			{Kind: SuffixStableUpdate, Text: "+deb11u2", Release: "11", Number: "2"},
+			{Kind: SuffixBinNMU, Text: "+b1", Number: "1"},
+		}},
+		{"1.2-3ubuntu1~22.04.1", "1.2-3", false, true, []Suffix{
+			{Kind: SuffixUbuntu, Text: "ubuntu1", Number: "1"},
+			{Kind: SuffixUbuntuRelease, Text: "~22.04.1", Release: "22.04", Number: "1"},
+		}},
+		{"1.2-ubuntu1", "1.2-ubuntu1", false, false, nil},
+	} {
+		o := ClassifyOrigin(mustVersion(t, tt.v))
+		assert.Equal(t, tt.base, o.Base.String(), tt.v)
+		assert.Equal(t, tt.nmu, o.NMU, tt.v)
+		assert.Equal(t, tt.ubuntu, o.IsUbuntu(), tt.v)
+		assert.Equal(t, tt.suffixes, o.Suffixes, tt.v)
+	}
+}
+
+func TestOriginSuffix(t *testing.T) {
+	o := ClassifyOrigin(mustVersion(t, "1.2-3+deb11u1+deb11u2"))
+	if s := o.Suffix(SuffixStableUpdate); assert.NotNil(t, s) {
+		assert.Equal(t, "2", s.Number)
+	}
+	assert.Nil(t, o.Suffix(SuffixBackport))
+	assert.Equal(t, "stable-update", SuffixStableUpdate.String())
+}
+
+// TestSuffixPatternsAgree checks that StripDistributionSuffix strips exactly the suffixes that ClassifyOrigin finds, for
+// every kind of suffix.
+func TestSuffixPatternsAgree(t *testing.T) {
+	examples := map[SuffixKind][]string{
+		SuffixBinNMU:        {"+b1"},
+		SuffixNMU:           {"+nmu2"},
+		SuffixStableUpdate:  {"+deb11u2", "~deb12u1"},
+		SuffixBackport:      {"~bpo11+1"},
+		SuffixPPA:           {"~ppa", "~ppa3", "~ppa1.2"},
+		SuffixUbuntuRelease: {"~22.04", "~22.04.1"},
+		SuffixUbuntuRebuild: {"build1"},
+		SuffixUbuntu:        {"ubuntu4", "ubuntu0.22.04.1"},
+	}
+	for _, p := range suffixPatterns {
+		if !assert.NotEmpty(t, examples[p.kind], p.kind.String()) {
+			continue
+		}
+		for _, suffix := range examples[p.kind] {
+			for _, base := range []string{"1.2-3", "1.2"} {
+				v := mustVersion(t, base+suffix)
+				o := ClassifyOrigin(v)
+				if assert.Len(t, o.Suffixes, 1, v.String()) {
+					assert.Equal(t, p.kind, o.Suffixes[0].Kind, v.String())
+				}
+				stripped, text := StripDistributionSuffix(v)
+				assert.Equal(t, suffix, text, v.String())
+				assert.Equal(t, base, stripped.String(), v.String())
+				assert.Equal(t, o.Base, stripped, v.String())
+			}
+		}
+	}
+}
@@ -0,0 +1,30 @@
+#!/bin/sh
+# Recomputes the expected results in dpkg-compare.txt using the installed dpkg.
+set -eu
+cd "$(dirname "$0")"
+
+tmp=$(mktemp)
+trap 'rm -f "$tmp"' EXIT
+
+while IFS= read -r line; do
+	case "$line" in
+	'#'* | '')
+		printf '%s\n' "$line"
+		continue
+		;;
+	esac
+	set -- $line
+	a=$1 b=$3
+	if dpkg --compare-versions "$a" lt "$b"; then
+		op='<'
+	elif dpkg --compare-versions "$a" eq "$b"; then
+		op='='
+	else
+		op='>'
+	fi
+	printf '%s %s %s\n' "$a" "$op" "$b"
+done < dpkg-compare.txt > "$tmp"
+
+sed -i "s/^# Generated by dpkg [^ ]*\./# Generated by dpkg $(dpkg-query -W -f '${Version}' dpkg)./" "$tmp"
+mv "$tmp" dpkg-compare.txt
+trap - EXIT